@@ -0,0 +1,271 @@
+package solanaswapgo
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ArbitrageHop is one swap of an arbitrage cycle
+type ArbitrageHop struct {
+	AMM            string
+	InputMint      solana.PublicKey
+	InputAmount    uint64
+	InputDecimals  uint8
+	OutputMint     solana.PublicKey
+	OutputAmount   uint64
+	OutputDecimals uint8
+}
+
+// ArbitrageInfo is a cyclic route spending and receiving the same BaseMint. AmountIn and AmountOut are the base
+// mint spent and received by the hops and GrossProfit their difference. Spent is what the transaction paid on top
+// of the hops in the base mint: the fees paid in it and, when the base mint is SOL, the transaction fee and the
+// Jito tip. NetProfit is GrossProfit less Spent.
+type ArbitrageInfo struct {
+	Signers    []solana.PublicKey
+	Signatures []solana.Signature
+	Router     string
+
+	BaseMint     solana.PublicKey
+	BaseDecimals uint8
+	Hops         []ArbitrageHop
+
+	AmountIn    uint64
+	AmountOut   uint64
+	GrossProfit int64
+
+	Fees    []Fee
+	Cost    TransactionCost
+	JitoTip uint64
+	Spent   uint64
+
+	NetProfit int64
+}
+
+// ProcessArbitrage returns the arbitrage cycle of the decoded swap data, it fails when the hops don't start and
+// end with the same mint
+func (p *Parser) ProcessArbitrage(swapDatas []SwapData) (*ArbitrageInfo, error) {
+	hops := p.getSwapHops(swapDatas)
+	if len(hops) < 2 {
+		return nil, fmt.Errorf("not an arbitrage: %d hops", len(hops))
+	}
+	baseMint := hops[0].InputMint
+	if !hops[len(hops)-1].OutputMint.Equals(baseMint) {
+		return nil, fmt.Errorf("not an arbitrage: route from %s to %s", baseMint, hops[len(hops)-1].OutputMint)
+	}
+
+	arbitrage := &ArbitrageInfo{
+		Signers:      []solana.PublicKey{p.getSigner()},
+		Signatures:   p.txInfo.Signatures,
+		BaseMint:     baseMint,
+		BaseDecimals: hops[0].InputDecimals,
+		Hops:         hops,
+		Cost:         p.GetTransactionCost(),
+		JitoTip:      p.GetJitoTip(),
+	}
+	if router := p.detectRouter(); router != nil {
+		arbitrage.Router = router.Name
+	}
+
+	// the base mint may come back mid-route (X -> A -> X -> B -> X), the profit is the net flow over all hops
+	for _, hop := range hops {
+		if hop.InputMint.Equals(baseMint) {
+			arbitrage.AmountIn += hop.InputAmount
+		}
+		if hop.OutputMint.Equals(baseMint) {
+			arbitrage.AmountOut += hop.OutputAmount
+		}
+	}
+	arbitrage.GrossProfit = int64(arbitrage.AmountOut) - int64(arbitrage.AmountIn)
+
+	arbitrage.Fees = append(arbitrage.Fees, p.getBotFees()...)
+	for _, swapData := range swapDatas {
+		switch data := swapData.Data.(type) {
+		case *JupiterRouteInstruction:
+			if data.PlatformFee != nil {
+				arbitrage.Fees = append(arbitrage.Fees, p.jupiterPlatformFee(data.PlatformFee))
+			}
+		case *JupiterFeeEvent:
+			arbitrage.Fees = append(arbitrage.Fees, p.jupiterPlatformFee(data))
+		}
+	}
+	for _, fee := range arbitrage.Fees {
+		if fee.Mint.Equals(baseMint) {
+			arbitrage.Spent += fee.Amount
+		}
+	}
+	if baseMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		arbitrage.Spent += arbitrage.Cost.BaseFee + arbitrage.Cost.PriorityFee + arbitrage.JitoTip
+	}
+	arbitrage.NetProfit = arbitrage.GrossProfit - int64(arbitrage.Spent)
+
+	return arbitrage, nil
+}
+
+// getSwapHops returns the hops of the decoded swap data in execution order. Jupiter events take precedence over
+// the venue legs. Transfer-based legs are grouped per venue: a transfer out of the signer's accounts is the input
+// and a transfer into them the output, whatever their order, and consecutive transfers of the same mint on one
+// side are summed, such as the fees a venue takes from the input.
+func (p *Parser) getSwapHops(swapDatas []SwapData) []ArbitrageHop {
+	var jupiterHops []ArbitrageHop
+	for _, swapData := range swapDatas {
+		if event, ok := swapData.Data.(*JupiterSwapEventData); ok {
+			jupiterHops = append(jupiterHops, ArbitrageHop{
+				AMM:            event.AmmName,
+				InputMint:      event.InputMint,
+				InputAmount:    event.InputAmount,
+				InputDecimals:  event.InputMintDecimals,
+				OutputMint:     event.OutputMint,
+				OutputAmount:   event.OutputAmount,
+				OutputDecimals: event.OutputMintDecimals,
+			})
+		}
+	}
+	if len(jupiterHops) > 0 {
+		return jupiterHops
+	}
+
+	var hops []ArbitrageHop
+	var input, output *TokenTransfer
+	var legType SwapType
+	flush := func() {
+		if input != nil && output != nil && input.mint != output.mint {
+			hops = appendHop(hops, legType, *input, *output)
+		}
+		input, output = nil, nil
+	}
+
+	signer := p.getSigner()
+	seenData := make(map[interface{}]bool)
+	for _, swapData := range swapDatas {
+		if seenData[swapData.Data] || p.isBotFeeTransfer(swapData) {
+			continue
+		}
+		seenData[swapData.Data] = true
+
+		if event, ok := swapData.Data.(*PumpfunTradeEvent); ok {
+			flush()
+			sol := TokenTransfer{mint: NATIVE_SOL_MINT_PROGRAM_ID.String(), amount: event.SolAmount, decimals: 9}
+			token := TokenTransfer{mint: event.Mint.String(), amount: event.TokenAmount, decimals: p.splDecimalsMap[event.Mint.String()]}
+			if event.IsBuy {
+				hops = appendHop(hops, swapData.Type, sol, token)
+			} else {
+				hops = appendHop(hops, swapData.Type, token, sol)
+			}
+			continue
+		}
+
+		transfers := getTransfersFromSwapData(swapData)
+		if len(transfers) == 2 {
+			flush()
+			hops = appendHop(hops, swapData.Type, transfers[0], transfers[1])
+			continue
+		}
+		if len(transfers) != 1 {
+			continue
+		}
+
+		source, destination, ok := getTransferAccounts(swapData)
+		if !ok {
+			continue
+		}
+		var side **TokenTransfer
+		switch {
+		case p.isOwnedBy(source, signer):
+			side = &input
+		case p.isOwnedBy(destination, signer):
+			side = &output
+		default:
+			continue
+		}
+		if swapData.Type != legType {
+			flush()
+			legType = swapData.Type
+		}
+
+		transfer := transfers[0]
+		switch {
+		case *side == nil:
+			*side = &transfer
+		case (*side).mint == transfer.mint:
+			(*side).amount += transfer.amount
+		default:
+			flush()
+			*side = &transfer
+		}
+	}
+	flush()
+	return hops
+}
+
+// getTransferAccounts returns the source and destination accounts of a transfer
+func getTransferAccounts(swapData SwapData) (solana.PublicKey, solana.PublicKey, bool) {
+	var source, destination string
+	switch data := swapData.Data.(type) {
+	case *TransferData:
+		source, destination = data.Info.Source, data.Info.Destination
+	case *TransferCheck:
+		source, destination = data.Info.Source, data.Info.Destination
+	default:
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+	sourceKey, err := solana.PublicKeyFromBase58(source)
+	if err != nil {
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+	destinationKey, err := solana.PublicKeyFromBase58(destination)
+	if err != nil {
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+	return sourceKey, destinationKey, true
+}
+
+func appendHop(hops []ArbitrageHop, swapType SwapType, input, output TokenTransfer) []ArbitrageHop {
+	inputMint, err := solana.PublicKeyFromBase58(input.mint)
+	if err != nil {
+		return hops
+	}
+	outputMint, err := solana.PublicKeyFromBase58(output.mint)
+	if err != nil {
+		return hops
+	}
+	return append(hops, ArbitrageHop{
+		AMM:            string(swapType),
+		InputMint:      inputMint,
+		InputAmount:    input.amount,
+		InputDecimals:  input.decimals,
+		OutputMint:     outputMint,
+		OutputAmount:   output.amount,
+		OutputDecimals: output.decimals,
+	})
+}
+
+// reconcileArbitrage checks the gross profit against the signer's change of the base mint, fees paid in the base
+// mint on top of the hops are added back
+func (p *Parser) reconcileArbitrage(arbitrage *ArbitrageInfo) *Reconciliation {
+	if len(arbitrage.Signers) == 0 {
+		return nil
+	}
+	change := p.getTradeBalanceChanges(arbitrage.Signers[0])[arbitrage.BaseMint]
+	reconciliation := &Reconciliation{InputChange: change, OutputChange: change}
+
+	var botFees, includedFees int64
+	for _, fee := range arbitrage.Fees {
+		if !fee.Mint.Equals(arbitrage.BaseMint) {
+			continue
+		}
+		if fee.Kind == FeeKindBot {
+			botFees += int64(fee.Amount)
+		} else {
+			includedFees += int64(fee.Amount)
+		}
+	}
+	// SOL bot fees are already out of the SOL change
+	if arbitrage.BaseMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		botFees = 0
+	}
+
+	profit := change + botFees
+	reconciliation.Reconciled = profit == arbitrage.GrossProfit || profit == arbitrage.GrossProfit-includedFees
+	return reconciliation
+}
@@ -0,0 +1,127 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestProcessArbitrage(t *testing.T) {
+	traderSOL := testKey("trader/wsol")
+	traderMint := testKey("trader/mint")
+	b := newTestTx(testTrader)
+	b.tokenAccount(traderSOL, testTrader, solana.SolMint, 9, 1_000_000_000, 1_010_000_000)
+	b.tokenAccount(traderMint, testTrader, testMint, 6, 0, 0)
+	b.tokenAccount(testKey("solfi/sol"), testKey("solfi"), solana.SolMint, 9, 0, 0)
+	b.tokenAccount(testKey("solfi/mint"), testKey("solfi"), testMint, 6, 0, 0)
+	b.tokenAccount(testKey("humidifi/mint"), testKey("humidifi"), testMint, 6, 0, 0)
+	b.tokenAccount(testKey("humidifi/sol"), testKey("humidifi"), solana.SolMint, 9, 0, 0)
+
+	// SOL -> mint on SolFi and back to SOL on HumidiFi, landed with a tip
+	b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+	b.invoke(0, 2, SOLFI_PROGRAM_ID, []byte{7}, testKey("solfi"))
+	b.transfer(0, 3, traderSOL, testKey("solfi/sol"), testTrader, 1_000_000_000)
+	b.transfer(0, 3, testKey("solfi/mint"), traderMint, testKey("solfi"), 5_000)
+	b.invoke(0, 2, HUMIDIFI_PROGRAM_ID, []byte{7}, testKey("humidifi"))
+	b.transfer(0, 3, traderMint, testKey("humidifi/mint"), testTrader, 5_000)
+	b.transfer(0, 3, testKey("humidifi/sol"), traderSOL, testKey("humidifi"), 1_010_000_000)
+	b.instruction(solana.SystemProgramID, systemTransferData(1_000), testTrader, JitoTipAccounts[0])
+	b.meta.Fee = 5_000
+	b.setLamports(testTrader, 1_000_000_000, 1_000_000_000-6_000)
+
+	parser, swapDatas, swapInfo := b.parse(t)
+	arbitrage, err := parser.ProcessArbitrage(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ArbitrageHop{
+		{AMM: string(SOLFI), InputMint: solana.SolMint, InputAmount: 1_000_000_000, InputDecimals: 9, OutputMint: testMint, OutputAmount: 5_000, OutputDecimals: 6},
+		{AMM: string(HUMIDIFI), InputMint: testMint, InputAmount: 5_000, InputDecimals: 6, OutputMint: solana.SolMint, OutputAmount: 1_010_000_000, OutputDecimals: 9},
+	}
+	if len(arbitrage.Hops) != len(want) {
+		t.Fatalf("got %d hops, want %d", len(arbitrage.Hops), len(want))
+	}
+	for i := range want {
+		if arbitrage.Hops[i] != want[i] {
+			t.Errorf("hop %d %+v, want %+v", i, arbitrage.Hops[i], want[i])
+		}
+	}
+	if arbitrage.Router != "DFlow" || !arbitrage.Signers[0].Equals(testTrader) {
+		t.Errorf("router %q signer %s, want DFlow and the trader", arbitrage.Router, arbitrage.Signers[0])
+	}
+	// the transaction fee and the tip are paid in the base mint
+	if arbitrage.AmountIn != 1_000_000_000 || arbitrage.AmountOut != 1_010_000_000 || arbitrage.GrossProfit != 10_000_000 ||
+		arbitrage.Spent != 6_000 || arbitrage.NetProfit != 9_994_000 {
+		t.Errorf("in %d out %d gross %d spent %d net %d, want 1000000000 1010000000 10000000 6000 9994000",
+			arbitrage.AmountIn, arbitrage.AmountOut, arbitrage.GrossProfit, arbitrage.Spent, arbitrage.NetProfit)
+	}
+
+	if swapInfo.Arbitrage == nil {
+		t.Fatal("swap info without arbitrage")
+	}
+	assertSwap(t, swapInfo, solana.SolMint, 1_000_000_000, solana.SolMint, 1_010_000_000)
+	if len(swapInfo.AMMs) != 2 || swapInfo.AMMs[0] != string(SOLFI) || swapInfo.AMMs[1] != string(HUMIDIFI) {
+		t.Errorf("AMMs %v, want [%s %s]", swapInfo.AMMs, SOLFI, HUMIDIFI)
+	}
+	if swapInfo.Reconciliation == nil || !swapInfo.Reconciliation.Reconciled || swapInfo.Reconciliation.InputChange != 10_000_000 {
+		t.Errorf("reconciliation %+v, want the 10000000 profit reconciled", swapInfo.Reconciliation)
+	}
+}
+
+func TestProcessArbitrageOneWay(t *testing.T) {
+	b, _, _ := routedTestTx(false)
+	parser, swapDatas, swapInfo := b.parse(t)
+	if _, err := parser.ProcessArbitrage(swapDatas); err == nil {
+		t.Error("one-way route taken for an arbitrage")
+	}
+	if swapInfo.Arbitrage != nil {
+		t.Errorf("swap info arbitrage %+v, want none", swapInfo.Arbitrage)
+	}
+}
+
+// the legs of a venue are read by which side the trader is on: PumpSwap sends the base before taking the quote
+// and its protocol fee, a Whirlpool b-to-a swap pays out before taking the input
+func TestProcessArbitrageTransferDirection(t *testing.T) {
+	traderSOL := testKey("trader/wsol")
+	traderMint := testKey("trader/mint")
+	b := newTestTx(testTrader)
+	b.tokenAccount(traderSOL, testTrader, solana.SolMint, 9, 1_002_000_000, 1_010_000_000)
+	b.tokenAccount(traderMint, testTrader, testMint, 6, 0, 0)
+	b.tokenAccount(testKey("pumpswap/mint"), testKey("pumpswap"), testMint, 6, 0, 0)
+	b.tokenAccount(testKey("pumpswap/sol"), testKey("pumpswap"), solana.SolMint, 9, 0, 0)
+	b.tokenAccount(testKey("pumpswap/fee"), testKey("pumpswap/fee-recipient"), solana.SolMint, 9, 0, 0)
+	b.tokenAccount(testKey("orca/sol"), testKey("orca"), solana.SolMint, 9, 0, 0)
+	b.tokenAccount(testKey("orca/mint"), testKey("orca"), testMint, 6, 0, 0)
+
+	// SOL -> mint on PumpSwap, paying a 0.002 SOL protocol fee
+	b.instruction(PUMPFUN_AMM_PROGRAM_ID, []byte{1}, testKey("pumpswap"), testTrader)
+	b.transfer(0, 2, testKey("pumpswap/mint"), traderMint, testKey("pumpswap"), 5_000)
+	b.transfer(0, 2, traderSOL, testKey("pumpswap/sol"), testTrader, 1_000_000_000)
+	b.transfer(0, 2, traderSOL, testKey("pumpswap/fee"), testTrader, 2_000_000)
+	// mint -> SOL on a Whirlpool whose token A is SOL
+	b.instruction(ORCA_PROGRAM_ID, []byte{1}, testKey("orca"), testTrader)
+	b.transfer(1, 2, testKey("orca/sol"), traderSOL, testKey("orca"), 1_010_000_000)
+	b.transfer(1, 2, traderMint, testKey("orca/mint"), testTrader, 5_000)
+
+	parser, swapDatas, _ := b.parse(t)
+	arbitrage, err := parser.ProcessArbitrage(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ArbitrageHop{
+		{AMM: string(PUMP_FUN), InputMint: solana.SolMint, InputAmount: 1_002_000_000, InputDecimals: 9, OutputMint: testMint, OutputAmount: 5_000, OutputDecimals: 6},
+		{AMM: string(ORCA), InputMint: testMint, InputAmount: 5_000, InputDecimals: 6, OutputMint: solana.SolMint, OutputAmount: 1_010_000_000, OutputDecimals: 9},
+	}
+	if len(arbitrage.Hops) != len(want) {
+		t.Fatalf("got %d hops %+v, want %d", len(arbitrage.Hops), arbitrage.Hops, len(want))
+	}
+	for i := range want {
+		if arbitrage.Hops[i] != want[i] {
+			t.Errorf("hop %d %+v, want %+v", i, arbitrage.Hops[i], want[i])
+		}
+	}
+	if arbitrage.GrossProfit != 8_000_000 {
+		t.Errorf("gross profit %d, want 8000000", arbitrage.GrossProfit)
+	}
+}
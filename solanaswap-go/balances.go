@@ -0,0 +1,207 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// BalanceChange is the net change of an owner's balance of a mint over the transaction, computed from the pre and
+// post balances recorded in the transaction metadata. The lamports of a system account are reported with the wSOL
+// mint and Native set.
+type BalanceChange struct {
+	Owner    solana.PublicKey
+	Mint     solana.PublicKey
+	Decimals uint8
+	Native   bool
+	Pre      uint64
+	Post     uint64
+	Change   int64
+}
+
+// Reconciliation compares the amounts of a SwapInfo with the trader's actual balance changes, the SOL change is the
+// one left once fees, tips and rent are taken out (see SolBalanceChange.SwapLamports)
+type Reconciliation struct {
+	InputChange  int64
+	OutputChange int64
+	Reconciled   bool
+}
+
+type balanceKey struct {
+	owner solana.PublicKey
+	mint  solana.PublicKey
+}
+
+// GetBalanceChanges returns the net change of every owner's token balances and of every account's lamports,
+// sorted by owner and mint. Unchanged balances are left out.
+func (p *Parser) GetBalanceChanges() []BalanceChange {
+	if p.txMeta == nil {
+		return nil
+	}
+
+	changes := make(map[balanceKey]*BalanceChange)
+	tokenChange := func(owner, mint solana.PublicKey) *BalanceChange {
+		key := balanceKey{owner: owner, mint: mint}
+		if changes[key] == nil {
+			changes[key] = &BalanceChange{Owner: owner, Mint: mint}
+		}
+		return changes[key]
+	}
+
+	for _, balance := range p.txMeta.PreTokenBalances {
+		if balance.Owner == nil {
+			continue
+		}
+		amount, _ := strconv.ParseUint(balance.UiTokenAmount.Amount, 10, 64)
+		change := tokenChange(*balance.Owner, balance.Mint)
+		change.Decimals = balance.UiTokenAmount.Decimals
+		change.Pre += amount
+	}
+	for _, balance := range p.txMeta.PostTokenBalances {
+		if balance.Owner == nil {
+			continue
+		}
+		amount, _ := strconv.ParseUint(balance.UiTokenAmount.Amount, 10, 64)
+		change := tokenChange(*balance.Owner, balance.Mint)
+		change.Decimals = balance.UiTokenAmount.Decimals
+		change.Post += amount
+	}
+
+	var result []BalanceChange
+	for _, change := range changes {
+		change.Change = int64(change.Post) - int64(change.Pre)
+		if change.Change != 0 {
+			result = append(result, *change)
+		}
+	}
+
+	for i, key := range p.allAccountKeys {
+		if i >= len(p.txMeta.PreBalances) || i >= len(p.txMeta.PostBalances) {
+			break
+		}
+		pre, post := p.txMeta.PreBalances[i], p.txMeta.PostBalances[i]
+		if pre == post {
+			continue
+		}
+		result = append(result, BalanceChange{
+			Owner:    key,
+			Mint:     NATIVE_SOL_MINT_PROGRAM_ID,
+			Decimals: 9,
+			Native:   true,
+			Pre:      pre,
+			Post:     post,
+			Change:   int64(post) - int64(pre),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Owner.Equals(result[j].Owner) {
+			return result[i].Owner.String() < result[j].Owner.String()
+		}
+		if !result[i].Mint.Equals(result[j].Mint) {
+			return result[i].Mint.String() < result[j].Mint.String()
+		}
+		return !result[i].Native && result[j].Native
+	})
+	return result
+}
+
+// getTradeBalanceChanges returns the owner's net change per mint, with native and wrapped SOL combined into the
+// SOL it actually traded
+func (p *Parser) getTradeBalanceChanges(owner solana.PublicKey) map[solana.PublicKey]int64 {
+	deltas := make(map[solana.PublicKey]int64)
+	for _, change := range p.GetBalanceChanges() {
+		if !change.Owner.Equals(owner) || change.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+			continue
+		}
+		deltas[change.Mint] += change.Change
+	}
+	if solChange := p.GetSolBalanceChange(owner); solChange.SwapLamports != 0 {
+		deltas[NATIVE_SOL_MINT_PROGRAM_ID] = solChange.SwapLamports
+	}
+	return deltas
+}
+
+// swapInfoFromBalanceChanges fills in a swap from the signer's balance changes when it spent exactly one mint and
+// received exactly one other, it is the fallback for transactions no decoder recognised
+func (p *Parser) swapInfoFromBalanceChanges(swapInfo *SwapInfo) error {
+	if len(swapInfo.Signers) == 0 {
+		return fmt.Errorf("no signer")
+	}
+
+	var spent, received []solana.PublicKey
+	deltas := p.getTradeBalanceChanges(swapInfo.Signers[0])
+	for mint, delta := range deltas {
+		switch {
+		case delta < 0:
+			spent = append(spent, mint)
+		case delta > 0:
+			received = append(received, mint)
+		}
+	}
+	if len(spent) != 1 || len(received) != 1 {
+		return fmt.Errorf("signer balance changes are not a swap (%d mints spent, %d received)", len(spent), len(received))
+	}
+
+	swapInfo.TokenInMint = spent[0]
+	swapInfo.TokenInAmount = uint64(-deltas[spent[0]])
+	swapInfo.TokenInDecimals = p.splDecimalsMap[spent[0].String()]
+	swapInfo.TokenOutMint = received[0]
+	swapInfo.TokenOutAmount = uint64(deltas[received[0]])
+	swapInfo.TokenOutDecimals = p.splDecimalsMap[received[0].String()]
+	swapInfo.AMMs = []string{string(UNKNOWN)}
+	return nil
+}
+
+// reconcile checks the swap amounts against the signer's balance changes
+func (p *Parser) reconcile(swapInfo *SwapInfo) *Reconciliation {
+	if len(swapInfo.Signers) == 0 {
+		return nil
+	}
+	deltas := p.getTradeBalanceChanges(swapInfo.Signers[0])
+	reconciliation := &Reconciliation{
+		InputChange:  deltas[swapInfo.TokenInMint],
+		OutputChange: deltas[swapInfo.TokenOutMint],
+	}
+
+	// platform fees may be included in the reported amounts, bot fees are charged on top of them and SOL ones
+	// are already out of the SOL change
+	includedFees := make(map[solana.PublicKey]int64)
+	botFees := make(map[solana.PublicKey]int64)
+	for _, fee := range swapInfo.Fees {
+		switch {
+		case fee.Kind != FeeKindBot:
+			includedFees[fee.Mint] += int64(fee.Amount)
+		case !fee.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID):
+			botFees[fee.Mint] += int64(fee.Amount)
+		}
+	}
+
+	spent := -reconciliation.InputChange - botFees[swapInfo.TokenInMint]
+	received := reconciliation.OutputChange + botFees[swapInfo.TokenOutMint]
+	inputMatches := spent == int64(swapInfo.TokenInAmount) || spent == int64(swapInfo.TokenInAmount)+includedFees[swapInfo.TokenInMint]
+	outputMatches := received == int64(swapInfo.TokenOutAmount) || received == int64(swapInfo.TokenOutAmount)-includedFees[swapInfo.TokenOutMint]
+	reconciliation.Reconciled = inputMatches && outputMatches
+	return reconciliation
+}
+
+// finalizeSwapInfo attaches the signer's SOL accounting to swaps with a SOL leg, reconciles the swap amounts with
+// the signer's balance changes and values the swap when the parser has a PriceOracle
+func (p *Parser) finalizeSwapInfo(swapInfo *SwapInfo) *SwapInfo {
+	if len(swapInfo.Signers) == 0 {
+		return swapInfo
+	}
+	if swapInfo.TokenInMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || swapInfo.TokenOutMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+		change := p.GetSolBalanceChange(swapInfo.Signers[0])
+		swapInfo.SOL = &change
+	}
+	swapInfo.Reconciliation = p.reconcile(swapInfo)
+	if p.PriceOracle != nil {
+		if err := swapInfo.FillValueUSD(p.PriceOracle); err != nil {
+			p.Log.Debugf("swap not valued: %s", err)
+		}
+	}
+	return swapInfo
+}
@@ -0,0 +1,133 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestGetBalanceChanges(t *testing.T) {
+	usdc := testKey("usdc")
+	friend := testKey("friend")
+	b := newTestTx(testTrader)
+	b.instruction(solana.MemoProgramID, []byte("gm"), testTrader)
+	// the trader's two USDC accounts are summed, unchanged balances are left out
+	b.tokenAccount(testKey("trader/usdc-1"), testTrader, usdc, 6, 100, 40)
+	b.tokenAccount(testKey("trader/usdc-2"), testTrader, usdc, 6, 10, 20)
+	b.tokenAccount(testKey("trader/mint"), testTrader, testMint, 6, 7, 7)
+	b.tokenAccount(testKey("friend/usdc"), friend, usdc, 6, 0, 50)
+	b.setLamports(testTrader, 1_000_000, 995_000)
+	b.setLamports(friend, 20, 20)
+
+	want := []BalanceChange{
+		{Owner: testTrader, Mint: usdc, Decimals: 6, Pre: 110, Post: 60, Change: -50},
+		{Owner: testTrader, Mint: NATIVE_SOL_MINT_PROGRAM_ID, Decimals: 9, Native: true, Pre: 1_000_000, Post: 995_000, Change: -5_000},
+		{Owner: friend, Mint: usdc, Decimals: 6, Pre: 0, Post: 50, Change: 50},
+	}
+	changes := b.parser(t).GetBalanceChanges()
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes %+v, want %d", len(changes), changes, len(want))
+	}
+	for _, w := range want {
+		found := false
+		for _, change := range changes {
+			if change == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing change %+v in %+v", w, changes)
+		}
+	}
+	for i := 1; i < len(changes); i++ {
+		if changes[i-1].Owner.String() > changes[i].Owner.String() {
+			t.Errorf("changes not sorted by owner: %s before %s", changes[i-1].Owner, changes[i].Owner)
+		}
+	}
+}
+
+func TestBalanceChangeFallback(t *testing.T) {
+	usdc := testKey("usdc")
+	b := newTestTx(testTrader)
+	// a program no decoder knows, the swap is read from the trader's balances
+	b.instruction(testKey("unknown-program"), []byte{1}, testTrader)
+	b.tokenAccount(testKey("trader/usdc"), testTrader, usdc, 6, 100_000_000, 0)
+	b.tokenAccount(testKey("trader/mint"), testTrader, testMint, 9, 0, 5_000_000_000)
+
+	parser := b.parser(t)
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapDatas) != 0 {
+		t.Fatalf("got %d swap data, want none", len(swapDatas))
+	}
+	// the fallback is opt-in
+	if _, err := parser.ProcessSwapData(swapDatas); err == nil || err.Error() != "no swap data provided" {
+		t.Fatalf("got error %v without DetectBalanceSwaps, want no swap data provided", err)
+	}
+	parser.DetectBalanceSwaps = true
+	swapInfo, err := parser.ProcessSwapData(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSwap(t, swapInfo, usdc, 100_000_000, testMint, 5_000_000_000)
+	if swapInfo.TokenInDecimals != 6 || swapInfo.TokenOutDecimals != 9 {
+		t.Errorf("decimals %d/%d, want 6/9", swapInfo.TokenInDecimals, swapInfo.TokenOutDecimals)
+	}
+	if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(UNKNOWN) {
+		t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, UNKNOWN)
+	}
+	if swapInfo.Reconciliation == nil || !swapInfo.Reconciliation.Reconciled {
+		t.Errorf("reconciliation %+v, want reconciled", swapInfo.Reconciliation)
+	}
+
+	// spending two mints is not a swap
+	b = newTestTx(testTrader)
+	b.instruction(testKey("unknown-program"), []byte{1}, testTrader)
+	b.tokenAccount(testKey("trader/usdc"), testTrader, usdc, 6, 100, 0)
+	b.tokenAccount(testKey("trader/mint"), testTrader, testMint, 9, 100, 0)
+	parser = b.parser(t)
+	parser.DetectBalanceSwaps = true
+	if _, err := parser.ProcessSwapData(nil); err == nil {
+		t.Error("balance changes spending two mints were taken for a swap")
+	}
+}
+
+func TestReconciliation(t *testing.T) {
+	usdc := testKey("usdc")
+	tests := []struct {
+		name     string
+		received uint64
+		want     Reconciliation
+	}{
+		{name: "matching", received: 5_000, want: Reconciliation{InputChange: -100_000, OutputChange: 5_000, Reconciled: true}},
+		// the trader got less than the transfers say, e.g. a transfer fee the decoder doesn't see
+		{name: "short", received: 4_990, want: Reconciliation{InputChange: -100_000, OutputChange: 4_990}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := testPool{
+				address: testKey("pool"),
+				vaultA:  testKey("pool/vault-a"),
+				vaultB:  testKey("pool/vault-b"),
+				userA:   testKey("trader/usdc"),
+				userB:   testKey("trader/mint"),
+			}
+			b := newTestTx(testTrader)
+			b.tokenAccount(pool.vaultA, pool.address, usdc, 6, 1_000_000, 1_100_000)
+			b.tokenAccount(pool.vaultB, pool.address, testMint, 6, 1_000_000, 995_000)
+			b.tokenAccount(pool.userA, testTrader, usdc, 6, 100_000, 0)
+			b.tokenAccount(pool.userB, testTrader, testMint, 6, 0, tt.received)
+			b.instruction(SOLFI_PROGRAM_ID, []byte{7}, pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB)
+			b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 100_000)
+			b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 5_000)
+
+			_, _, swapInfo := b.parse(t)
+			assertSwap(t, swapInfo, usdc, 100_000, testMint, 5_000)
+			if swapInfo.Reconciliation == nil || *swapInfo.Reconciliation != tt.want {
+				t.Errorf("reconciliation %+v, want %+v", swapInfo.Reconciliation, tt.want)
+			}
+		})
+	}
+}
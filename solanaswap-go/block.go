@@ -0,0 +1,80 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// TransactionTip is the Jito tip paid by a transaction of a block
+type TransactionTip struct {
+	Signature solana.Signature
+	Amount    uint64
+}
+
+// BlockResult holds the swaps, liquidity events, pool creations and Jito tips of the successful transactions of a block, Indexes
+// holds the position in the block of each swap's transaction
+type BlockResult struct {
+	Slot      uint64
+	BlockTime time.Time
+	Swaps     []*SwapInfo
+	Indexes   []int
+	Liquidity []LiquidityEvent
+	Pools     []PoolCreated
+	Tips      []TransactionTip
+	JitoTips  uint64
+}
+
+// ParseBlock parses every successful transaction of a block fetched with full transaction details, transactions
+// which are not swaps only contribute their tips. Swap timestamps are set to the block time when it is known.
+func ParseBlock(slot uint64, block *rpc.GetBlockResult) (*BlockResult, error) {
+	if block == nil {
+		return nil, fmt.Errorf("no block provided")
+	}
+
+	result := &BlockResult{Slot: slot}
+	if block.BlockTime != nil {
+		result.BlockTime = block.BlockTime.Time()
+	}
+
+	for i, txWithMeta := range block.Transactions {
+		if txWithMeta.Meta == nil || txWithMeta.Meta.Err != nil {
+			continue
+		}
+		tx, err := txWithMeta.GetTransaction()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction %d: %w", i, err)
+		}
+		parser, err := NewTransactionParserFromTransaction(tx, txWithMeta.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create parser for transaction %d: %w", i, err)
+		}
+		parser.blockTime = result.BlockTime
+
+		if tip := parser.GetJitoTip(); tip > 0 {
+			result.Tips = append(result.Tips, TransactionTip{Signature: tx.Signatures[0], Amount: tip})
+			result.JitoTips += tip
+		}
+
+		result.Liquidity = append(result.Liquidity, parser.GetLiquidityEvents()...)
+		result.Pools = append(result.Pools, parser.GetPoolsCreated()...)
+
+		swapDatas, err := parser.ParseTransaction()
+		if err != nil || len(swapDatas) == 0 {
+			continue
+		}
+		swapInfo, err := parser.ProcessSwapData(swapDatas)
+		if err != nil {
+			continue
+		}
+		if block.BlockTime != nil {
+			swapInfo.Timestamp = result.BlockTime
+		}
+		result.Swaps = append(result.Swaps, swapInfo)
+		result.Indexes = append(result.Indexes, i)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,208 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// InstructionNode is an instruction of the transaction's CPI call tree. Outer instructions are the roots with
+// InnerIndex -1 and StackHeight 1, inner instructions hang under the instruction that invoked them.
+type InstructionNode struct {
+	ProgramID   solana.PublicKey
+	OuterIndex  int
+	InnerIndex  int
+	StackHeight int
+	Instruction solana.CompiledInstruction `json:"-"`
+	Parent      *InstructionNode           `json:"-"`
+	Children    []*InstructionNode
+}
+
+// Venue returns the program of the nearest swap venue (see ProgramLabels) the instruction was executed for, the
+// instruction itself included, or the zero key when it was not executed for a known venue
+func (n *InstructionNode) Venue() solana.PublicKey {
+	for node := n; node != nil; node = node.Parent {
+		if _, ok := ProgramLabels[node.ProgramID]; ok {
+			return node.ProgramID
+		}
+	}
+	return solana.PublicKey{}
+}
+
+// String renders the subtree one instruction per line, indented by stack height, for debugging
+func (n *InstructionNode) String() string {
+	var b strings.Builder
+	var write func(node *InstructionNode)
+	write = func(node *InstructionNode) {
+		label := node.ProgramID.String()
+		if name, ok := ProgramLabels[node.ProgramID]; ok {
+			label = fmt.Sprintf("%s (%s)", name, node.ProgramID)
+		}
+		fmt.Fprintf(&b, "%s%d.%d %s\n", strings.Repeat("  ", node.StackHeight-1), node.OuterIndex, node.InnerIndex, label)
+		for _, child := range node.Children {
+			write(child)
+		}
+	}
+	write(n)
+	return b.String()
+}
+
+// GetCallTree returns one call tree per outer instruction, rebuilt from the stack heights of the inner
+// instructions. Transactions from before stack heights were recorded get every inner instruction directly
+// under its outer instruction.
+func (p *Parser) GetCallTree() []*InstructionNode {
+	if p.callTree != nil {
+		return p.callTree
+	}
+
+	roots := make([]*InstructionNode, len(p.txInfo.Message.Instructions))
+	for i, instr := range p.txInfo.Message.Instructions {
+		roots[i] = &InstructionNode{
+			ProgramID:   p.allAccountKeys[instr.ProgramIDIndex],
+			OuterIndex:  i,
+			InnerIndex:  -1,
+			StackHeight: 1,
+			Instruction: instr,
+		}
+	}
+
+	if p.txMeta != nil {
+		for _, innerSet := range p.txMeta.InnerInstructions {
+			if int(innerSet.Index) >= len(roots) {
+				continue
+			}
+			stack := []*InstructionNode{roots[innerSet.Index]}
+			for j, inner := range innerSet.Instructions {
+				height := int(inner.StackHeight)
+				if height < 2 {
+					height = 2
+				}
+				// pop back to the invoking instruction, one level above this one
+				for len(stack) > 1 && stack[len(stack)-1].StackHeight >= height {
+					stack = stack[:len(stack)-1]
+				}
+				parent := stack[len(stack)-1]
+				node := &InstructionNode{
+					ProgramID:   p.allAccountKeys[inner.ProgramIDIndex],
+					OuterIndex:  int(innerSet.Index),
+					InnerIndex:  j,
+					StackHeight: parent.StackHeight + 1,
+					Instruction: p.convertRPCToSolanaInstruction(inner),
+					Parent:      parent,
+				}
+				parent.Children = append(parent.Children, node)
+				stack = append(stack, node)
+			}
+		}
+	}
+
+	p.callTree = roots
+	return roots
+}
+
+// hasStackHeights reports whether the inner instructions of an outer instruction carry their stack height
+func (p *Parser) hasStackHeights(instructionIndex int) bool {
+	if p.txMeta == nil {
+		return false
+	}
+	for _, innerSet := range p.txMeta.InnerInstructions {
+		if int(innerSet.Index) != instructionIndex {
+			continue
+		}
+		for _, inner := range innerSet.Instructions {
+			if inner.StackHeight > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// descendants returns the instructions invoked under the node, in execution order
+func (n *InstructionNode) descendants() []*InstructionNode {
+	var nodes []*InstructionNode
+	var walk func(node *InstructionNode)
+	walk = func(node *InstructionNode) {
+		for _, child := range node.Children {
+			nodes = append(nodes, child)
+			walk(child)
+		}
+	}
+	walk(n)
+	return nodes
+}
+
+// getInnerNodes returns the inner instructions of an outer instruction as call tree nodes, in execution order.
+// While an invocation is in scope only its subtree is returned.
+func (p *Parser) getInnerNodes(instructionIndex int) []*InstructionNode {
+	if p.scope != nil && p.scope.OuterIndex == instructionIndex {
+		return p.scope.descendants()
+	}
+	tree := p.GetCallTree()
+	if instructionIndex >= len(tree) {
+		return nil
+	}
+	return tree[instructionIndex].descendants()
+}
+
+// getInstructionNodes returns an outer instruction and its inner instructions as call tree nodes, in execution
+// order. While an invocation is in scope only the invocation and its subtree are returned.
+func (p *Parser) getInstructionNodes(instructionIndex int) []*InstructionNode {
+	if p.scope != nil && p.scope.OuterIndex == instructionIndex {
+		return append([]*InstructionNode{p.scope}, p.scope.descendants()...)
+	}
+	tree := p.GetCallTree()
+	if instructionIndex >= len(tree) {
+		return nil
+	}
+	return append([]*InstructionNode{tree[instructionIndex]}, tree[instructionIndex].descendants()...)
+}
+
+// executedFor reports whether the nearest venue above an instruction is one of the given programs, programs
+// such as vaults called by a venue are looked through
+func (n *InstructionNode) executedFor(venues []solana.PublicKey) bool {
+	for node := n.Parent; node != nil; node = node.Parent {
+		if containsProgram(venues, node.ProgramID) {
+			return true
+		}
+		if _, ok := ProgramLabels[node.ProgramID]; ok {
+			return false
+		}
+	}
+	return false
+}
+
+// processVenueTransfers collects the Transfer and TransferChecked instructions executed for one of the given venue
+// programs under an outer instruction, so that a router's fee transfers and the legs of its other venues are left
+// out. Without stack heights every transfer under the outer instruction is collected.
+func (p *Parser) processVenueTransfers(instructionIndex int, swapType SwapType, venues ...solana.PublicKey) []SwapData {
+	attribute := p.hasStackHeights(instructionIndex)
+
+	var swaps []SwapData
+	for _, node := range p.getInnerNodes(instructionIndex) {
+		if attribute && (!node.executedFor(venues) || p.underNonSwapInstruction(node)) {
+			continue
+		}
+		switch {
+		case p.isTransfer(node.Instruction):
+			if transfer := p.processTransfer(node.Instruction); transfer != nil {
+				swaps = append(swaps, SwapData{Type: swapType, Data: transfer})
+			}
+		case p.isTransferCheck(node.Instruction):
+			if transfer := p.processTransferCheck(node.Instruction); transfer != nil {
+				swaps = append(swaps, SwapData{Type: swapType, Data: transfer})
+			}
+		}
+	}
+	return swaps
+}
+
+func containsProgram(programs []solana.PublicKey, programID solana.PublicKey) bool {
+	for _, program := range programs {
+		if program.Equals(programID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// routedTestTx is a router instruction sending the trade through SolFi then HumidiFi, with a fee transfer of its own
+func routedTestTx(noStackHeights bool) (*testTx, testPool, testPool) {
+	usdc := testKey("usdc")
+	b := newTestTx(testTrader)
+	b.noStackHeights = noStackHeights
+	first := newTestPool(b, "solfi-pool", testTrader, usdc, 6, testMint, 6)
+	second := newTestPool(b, "humidifi-pool", testTrader, testMint, 6, testKey("other-mint"), 6)
+	b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+	b.invoke(0, 2, SOLFI_PROGRAM_ID, []byte{7}, first.address)
+	b.transfer(0, 3, first.userA, first.vaultA, testTrader, 100_000)
+	b.transfer(0, 3, first.vaultB, first.userB, first.address, 5_000)
+	b.invoke(0, 2, HUMIDIFI_PROGRAM_ID, []byte{7}, second.address)
+	b.transfer(0, 3, second.userA, second.vaultA, testTrader, 5_000)
+	b.transfer(0, 3, second.vaultB, second.userB, second.address, 42)
+	b.transfer(0, 2, first.userA, testKey("router-fee"), testTrader, 100)
+	return b, first, second
+}
+
+func TestGetCallTree(t *testing.T) {
+	b, _, _ := routedTestTx(false)
+	tree := b.parser(t).GetCallTree()
+	if len(tree) != 1 {
+		t.Fatalf("got %d roots, want 1", len(tree))
+	}
+	root := tree[0]
+	if !root.ProgramID.Equals(DFLOW_PROGRAM_ID) || root.InnerIndex != -1 || root.StackHeight != 1 || len(root.Children) != 3 {
+		t.Fatalf("root %s.%d at height %d with %d children, want the router with 3", root.ProgramID, root.InnerIndex, root.StackHeight, len(root.Children))
+	}
+
+	solfi, humidifi, fee := root.Children[0], root.Children[1], root.Children[2]
+	for _, venue := range []*InstructionNode{solfi, humidifi} {
+		if venue.StackHeight != 2 || venue.Parent != root || len(venue.Children) != 2 {
+			t.Errorf("venue %s at height %d with %d children, want height 2 with 2", venue.ProgramID, venue.StackHeight, len(venue.Children))
+		}
+		for _, transfer := range venue.Children {
+			if transfer.StackHeight != 3 || !transfer.Venue().Equals(venue.ProgramID) {
+				t.Errorf("transfer %d.%d executed for %s, want %s", transfer.OuterIndex, transfer.InnerIndex, transfer.Venue(), venue.ProgramID)
+			}
+		}
+	}
+	if !humidifi.ProgramID.Equals(HUMIDIFI_PROGRAM_ID) || humidifi.InnerIndex != 3 {
+		t.Errorf("second venue %s at inner index %d, want HumidiFi at 3", humidifi.ProgramID, humidifi.InnerIndex)
+	}
+	if !fee.Venue().IsZero() || len(fee.Children) != 0 {
+		t.Errorf("router fee transfer executed for %s, want no venue", fee.Venue())
+	}
+	if descendants := root.descendants(); len(descendants) != 7 || descendants[4] != humidifi.Children[0] {
+		t.Errorf("got %d descendants, want 7 in execution order", len(descendants))
+	}
+
+	want := fmt.Sprintf("0.-1 %s\n  0.0 SolFi (%s)\n    0.1 %s\n", DFLOW_PROGRAM_ID, SOLFI_PROGRAM_ID, solana.TokenProgramID)
+	if rendered := root.String(); len(rendered) < len(want) || rendered[:len(want)] != want {
+		t.Errorf("rendered tree starts\n%s\nwant\n%s", rendered, want)
+	}
+}
+
+func TestGetCallTreeWithoutStackHeights(t *testing.T) {
+	b, _, _ := routedTestTx(true)
+	parser := b.parser(t)
+	root := parser.GetCallTree()[0]
+	if len(root.Children) != 7 {
+		t.Fatalf("got %d children, want every inner instruction under the outer one", len(root.Children))
+	}
+	for _, child := range root.Children {
+		if child.StackHeight != 2 || len(child.Children) != 0 {
+			t.Errorf("inner instruction %d at height %d with %d children, want a leaf at height 2", child.InnerIndex, child.StackHeight, len(child.Children))
+		}
+	}
+	if parser.hasStackHeights(0) {
+		t.Error("stack heights reported for a transaction without them")
+	}
+}
+
+func TestRouterLegsPerVenue(t *testing.T) {
+	b, first, second := routedTestTx(false)
+	_, swapDatas, swapInfo := b.parse(t)
+
+	// each venue gets its own two transfers, the router's fee transfer is left out
+	counts := make(map[SwapType]int)
+	for _, swapData := range swapDatas {
+		counts[swapData.Type]++
+	}
+	if len(swapDatas) != 4 || counts[SOLFI] != 2 || counts[HUMIDIFI] != 2 {
+		t.Errorf("swap data per venue %v, want 2 SolFi and 2 HumidiFi", counts)
+	}
+	assertSwap(t, swapInfo, first.mintA, 100_000, second.mintB, 42)
+	if len(swapInfo.AMMs) != 2 || swapInfo.AMMs[0] != string(SOLFI) || swapInfo.AMMs[1] != string(HUMIDIFI) {
+		t.Errorf("AMMs %v, want [%s %s]", swapInfo.AMMs, SOLFI, HUMIDIFI)
+	}
+}
+
+// a route split over two pools of the same venue, both legs moving the same amounts, reports both legs once: equal
+// legs are told apart by identity, not by mint and amount
+func TestRouterSameVenueSameAmounts(t *testing.T) {
+	for _, noStackHeights := range []bool{false, true} {
+		t.Run(fmt.Sprintf("no stack heights %v", noStackHeights), func(t *testing.T) {
+			b := newTestTx(testTrader)
+			b.noStackHeights = noStackHeights
+			first := newTestPool(b, "solfi-pool-1", testTrader, testUSDC, 6, testMint, 6)
+			second := newTestPool(b, "solfi-pool-2", testTrader, testUSDC, 6, testMint, 6)
+			b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+			for _, pool := range []testPool{first, second} {
+				b.invoke(0, 2, SOLFI_PROGRAM_ID, []byte{7}, pool.address)
+				b.transfer(0, 3, first.userA, pool.vaultA, testTrader, 100_000)
+				b.transfer(0, 3, pool.vaultB, first.userB, pool.address, 5_000)
+			}
+
+			_, swapDatas, swapInfo := b.parse(t)
+			seen := make(map[interface{}]bool)
+			for _, swapData := range swapDatas {
+				if swapData.Type != SOLFI {
+					t.Errorf("swap data of %s, want SolFi", swapData.Type)
+				}
+				seen[swapData.Data] = true
+			}
+			if len(swapDatas) != 4 || len(seen) != 4 {
+				t.Errorf("got %d swap data, %d distinct, want the 4 transfers", len(swapDatas), len(seen))
+			}
+			assertSwap(t, swapInfo, testUSDC, 200_000, testMint, 10_000)
+			if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(SOLFI) {
+				t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, SOLFI)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package solanaswapgo
 
 import (
 	"bytes"
+	"encoding/binary"
 
 	"github.com/gagliardetto/solana-go"
 	"github.com/mr-tron/base58"
@@ -32,6 +33,29 @@ func (p *Parser) isTransfer(instr solana.CompiledInstruction) bool {
 	return true
 }
 
+// isSystemTransfer checks if the instruction is a System Program transfer of lamports
+func (p *Parser) isSystemTransfer(instr solana.CompiledInstruction) bool {
+	if !p.allAccountKeys[instr.ProgramIDIndex].Equals(solana.SystemProgramID) {
+		return false
+	}
+
+	if len(instr.Accounts) < 2 || len(instr.Data) < 12 {
+		return false
+	}
+
+	if binary.LittleEndian.Uint32(instr.Data[:4]) != SYSTEM_TRANSFER_INSTRUCTION {
+		return false
+	}
+
+	for i := 0; i < 2; i++ {
+		if int(instr.Accounts[i]) >= len(p.allAccountKeys) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // isTransferCheck checks if the instruction is a token transfer check (Meteora)
 func (p *Parser) isTransferCheck(instr solana.CompiledInstruction) bool {
 	progID := p.allAccountKeys[instr.ProgramIDIndex]
@@ -78,3 +102,15 @@ func (p *Parser) isJupiterRouteEventInstruction(inst solana.CompiledInstruction)
 	}
 	return bytes.Equal(decodedBytes[:16], JupiterRouteEventDiscriminator[:])
 }
+
+// isAnchorEventInstruction checks if the instruction is a self-CPI anchor event emitted by the given program
+func (p *Parser) isAnchorEventInstruction(inst solana.CompiledInstruction, programID solana.PublicKey, discriminator [16]byte) bool {
+	if !p.allAccountKeys[inst.ProgramIDIndex].Equals(programID) || len(inst.Data) < 16 {
+		return false
+	}
+	decodedBytes, err := base58.Decode(inst.Data.String())
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(decodedBytes[:16], discriminator[:])
+}
@@ -0,0 +1,105 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	COMPUTE_BUDGET_SET_COMPUTE_UNIT_LIMIT_INSTRUCTION = 2
+	COMPUTE_BUDGET_SET_COMPUTE_UNIT_PRICE_INSTRUCTION = 3
+
+	MICRO_LAMPORTS_PER_LAMPORT = 1_000_000
+
+	// LAMPORTS_PER_SIGNATURE is the base fee charged for each transaction signature
+	LAMPORTS_PER_SIGNATURE = 5000
+
+	// DEFAULT_INSTRUCTION_COMPUTE_UNIT_LIMIT and MAX_COMPUTE_UNIT_LIMIT give the compute unit limit of transactions
+	// without a SetComputeUnitLimit instruction
+	DEFAULT_INSTRUCTION_COMPUTE_UNIT_LIMIT = 200_000
+	MAX_COMPUTE_UNIT_LIMIT                 = 1_400_000
+)
+
+// TransactionCost is what a transaction paid to be executed, fees are in lamports and the compute unit price
+// in micro-lamports as set by SetComputeUnitPrice
+type TransactionCost struct {
+	BaseFee              uint64
+	PriorityFee          uint64
+	ComputeUnitLimit     uint32
+	ComputeUnitsConsumed uint64
+	ComputeUnitPrice     uint64
+}
+
+// GetTransactionCost decodes the ComputeBudget instructions of the transaction and combines them with the fee and
+// compute units recorded in its metadata
+func (p *Parser) GetTransactionCost() TransactionCost {
+	var cost TransactionCost
+
+	limitSet := false
+	nonBudgetInstructions := 0
+	for _, instr := range p.txInfo.Message.Instructions {
+		if !p.allAccountKeys[instr.ProgramIDIndex].Equals(solana.ComputeBudget) {
+			nonBudgetInstructions++
+			continue
+		}
+		if len(instr.Data) == 0 {
+			continue
+		}
+		switch instr.Data[0] {
+		case COMPUTE_BUDGET_SET_COMPUTE_UNIT_LIMIT_INSTRUCTION:
+			if len(instr.Data) >= 5 {
+				cost.ComputeUnitLimit = binary.LittleEndian.Uint32(instr.Data[1:5])
+				limitSet = true
+			}
+		case COMPUTE_BUDGET_SET_COMPUTE_UNIT_PRICE_INSTRUCTION:
+			if len(instr.Data) >= 9 {
+				cost.ComputeUnitPrice = binary.LittleEndian.Uint64(instr.Data[1:9])
+			}
+		}
+	}
+	if !limitSet {
+		cost.ComputeUnitLimit = uint32(min(nonBudgetInstructions*DEFAULT_INSTRUCTION_COMPUTE_UNIT_LIMIT, MAX_COMPUTE_UNIT_LIMIT))
+	}
+
+	cost.BaseFee = uint64(len(p.txInfo.Signatures)) * LAMPORTS_PER_SIGNATURE
+	priorityFee := new(big.Int).Mul(new(big.Int).SetUint64(cost.ComputeUnitPrice), big.NewInt(int64(cost.ComputeUnitLimit)))
+	priorityFee.Add(priorityFee, big.NewInt(MICRO_LAMPORTS_PER_LAMPORT-1)).Div(priorityFee, big.NewInt(MICRO_LAMPORTS_PER_LAMPORT))
+	if priorityFee.IsUint64() {
+		cost.PriorityFee = priorityFee.Uint64()
+	}
+
+	if p.txMeta != nil {
+		// the fee recorded by the runtime is authoritative, it also covers precompile signatures
+		if p.txMeta.Fee >= cost.PriorityFee {
+			cost.BaseFee = p.txMeta.Fee - cost.PriorityFee
+		}
+		if p.txMeta.ComputeUnitsConsumed != nil {
+			cost.ComputeUnitsConsumed = *p.txMeta.ComputeUnitsConsumed
+		}
+	}
+
+	return cost
+}
+
+func isJitoTipAccount(account solana.PublicKey) bool {
+	for _, tipAccount := range JitoTipAccounts {
+		if tipAccount.Equals(account) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetJitoTip returns the lamports transferred to the Jito tip accounts, either by a top-level instruction or
+// inside a program's CPI, a non-zero tip means the transaction was most likely landed through a bundle
+func (p *Parser) GetJitoTip() uint64 {
+	var tip uint64
+	for _, instr := range p.getAllInstructions() {
+		if p.isSystemTransfer(instr) && isJitoTipAccount(p.allAccountKeys[instr.Accounts[1]]) {
+			tip += binary.LittleEndian.Uint64(instr.Data[4:12])
+		}
+	}
+	return tip
+}
@@ -0,0 +1,88 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func computeUnitLimitData(units uint32) []byte {
+	return binary.LittleEndian.AppendUint32([]byte{COMPUTE_BUDGET_SET_COMPUTE_UNIT_LIMIT_INSTRUCTION}, units)
+}
+
+func computeUnitPriceData(microLamports uint64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{COMPUTE_BUDGET_SET_COMPUTE_UNIT_PRICE_INSTRUCTION}, microLamports)
+}
+
+func TestGetTransactionCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget [][]byte
+		// instructions is the number of other instructions in the transaction
+		instructions int
+		fee          uint64
+		consumed     uint64
+		want         TransactionCost
+	}{
+		{
+			name:         "limit and price",
+			budget:       [][]byte{computeUnitLimitData(300_000), computeUnitPriceData(12_345)},
+			instructions: 1,
+			// the priority fee is rounded up to the lamport
+			fee:      5_000 + 3_704,
+			consumed: 123_456,
+			want:     TransactionCost{BaseFee: 5_000, PriorityFee: 3_704, ComputeUnitLimit: 300_000, ComputeUnitsConsumed: 123_456, ComputeUnitPrice: 12_345},
+		},
+		{
+			name:         "default limit",
+			budget:       [][]byte{computeUnitPriceData(1_000_000)},
+			instructions: 2,
+			fee:          5_000 + 400_000,
+			want:         TransactionCost{BaseFee: 5_000, PriorityFee: 400_000, ComputeUnitLimit: 400_000, ComputeUnitPrice: 1_000_000},
+		},
+		{
+			name:         "capped default limit",
+			instructions: 8,
+			// a precompile signature is charged like a transaction signature
+			fee:  10_000,
+			want: TransactionCost{BaseFee: 10_000, ComputeUnitLimit: MAX_COMPUTE_UNIT_LIMIT},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestTx(testTrader)
+			for _, data := range tt.budget {
+				b.instruction(solana.ComputeBudget, data)
+			}
+			for i := 0; i < tt.instructions; i++ {
+				b.instruction(solana.MemoProgramID, []byte("gm"), testTrader)
+			}
+			b.meta.Fee = tt.fee
+			if tt.consumed != 0 {
+				b.meta.ComputeUnitsConsumed = &tt.consumed
+			}
+
+			if cost := b.parser(t).GetTransactionCost(); cost != tt.want {
+				t.Errorf("got %+v, want %+v", cost, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetJitoTip(t *testing.T) {
+	b := newTestTx(testTrader)
+	b.instruction(solana.SystemProgramID, systemTransferData(1_000_000), testTrader, JitoTipAccounts[0])
+	// tips paid from a program's CPI are counted, transfers to other accounts are not
+	b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+	b.invoke(1, 2, solana.SystemProgramID, systemTransferData(500), testTrader, JitoTipAccounts[1])
+	b.invoke(1, 2, solana.SystemProgramID, systemTransferData(2_000_000), testTrader, testKey("friend"))
+
+	if tip := b.parser(t).GetJitoTip(); tip != 1_000_500 {
+		t.Errorf("tip %d, want 1000500", tip)
+	}
+
+	if tip := newTestTx(testTrader).parser(t).GetJitoTip(); tip != 0 {
+		t.Errorf("tip of a transaction without tips %d, want 0", tip)
+	}
+}
@@ -1,6 +1,8 @@
 package solanaswapgo
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 
@@ -19,32 +21,214 @@ type JupiterSwapEvent struct {
 
 type JupiterSwapEventData struct {
 	JupiterSwapEvent
+	AmmName            string
 	InputMintDecimals  uint8
 	OutputMintDecimals uint8
 }
 
-var JupiterRouteEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 64, 198, 205, 232, 38, 8, 113, 226}
+var (
+	JupiterRouteEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 64, 198, 205, 232, 38, 8, 113, 226}
+	JupiterFeeEventDiscriminator   = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 73, 79, 78, 127, 184, 213, 13, 220}
+
+	JUPITER_ROUTE_DISCRIMINATOR                                   = [8]byte{229, 23, 203, 151, 122, 227, 173, 42}
+	JUPITER_SHARED_ACCOUNTS_ROUTE_DISCRIMINATOR                   = [8]byte{193, 32, 155, 51, 65, 214, 156, 129}
+	JUPITER_EXACT_OUT_ROUTE_DISCRIMINATOR                         = [8]byte{208, 51, 239, 151, 123, 43, 237, 92}
+	JUPITER_SHARED_ACCOUNTS_EXACT_OUT_ROUTE_DISCRIMINATOR         = [8]byte{176, 209, 105, 168, 154, 125, 69, 62}
+	JUPITER_ROUTE_WITH_TOKEN_LEDGER_DISCRIMINATOR                 = [8]byte{150, 86, 71, 116, 167, 93, 14, 104}
+	JUPITER_SHARED_ACCOUNTS_ROUTE_WITH_TOKEN_LEDGER_DISCRIMINATOR = [8]byte{230, 121, 143, 80, 119, 159, 106, 170}
+)
+
+type JupiterFeeEvent struct {
+	Account solana.PublicKey
+	Mint    solana.PublicKey
+	Amount  uint64
+}
+
+// JupiterRouteInstruction holds the arguments of a Jupiter route instruction. The route plan itself is skipped,
+// the fixed-size arguments are read from the end of the instruction data. Exact-out routes set OutAmount and
+// QuotedInAmount, token ledger routes take their input amount from the ledger and leave InAmount at zero.
+// Jupiter v4 routes carry a minimum out amount instead of a quote and slippage, reported as QuotedOutAmount.
+type JupiterRouteInstruction struct {
+	ProgramID       solana.PublicKey
+	Name            string
+	InAmount        uint64
+	QuotedOutAmount uint64
+	OutAmount       uint64
+	QuotedInAmount  uint64
+	SlippageBps     uint16
+	PlatformFeeBps  uint8
+	ExactOut        bool
+	PlatformFee     *JupiterFeeEvent
+}
 
 func (p *Parser) processJupiterSwaps(instructionIndex int) []SwapData {
 	var swaps []SwapData
+	var feeEvents []*JupiterFeeEvent
 	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
 		if innerInstructionSet.Index == uint16(instructionIndex) {
 			for _, innerInstruction := range innerInstructionSet.Instructions {
-				if p.isJupiterRouteEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction)) {
+				switch {
+				case p.isJupiterRouteEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction)):
 					eventData, err := p.parseJupiterRouteEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction))
 					if err != nil {
-						p.Log.Errorf("error processing Pumpfun trade event: %s", err)
+						p.Log.Errorf("error processing Jupiter route event: %s", err)
 					}
 					if eventData != nil {
 						swaps = append(swaps, SwapData{Type: JUPITER, Data: eventData})
 					}
+				case p.isAnchorEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction), JUPITER_PROGRAM_ID, JupiterFeeEventDiscriminator):
+					feeEvent, err := p.parseJupiterFeeEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction))
+					if err != nil {
+						p.Log.Errorf("error processing Jupiter fee event: %s", err)
+					}
+					if feeEvent != nil {
+						feeEvents = append(feeEvents, feeEvent)
+					}
 				}
 			}
 		}
 	}
+
+	// a route takes at most one platform fee, fee events beyond the decoded routes are kept on their own
+	routes := p.getJupiterRouteInstructions(instructionIndex, JUPITER_PROGRAM_ID)
+	for i, route := range routes {
+		if i < len(feeEvents) {
+			route.PlatformFee = feeEvents[i]
+		}
+		swaps = append(swaps, SwapData{Type: JUPITER, Data: route})
+	}
+	for i := len(routes); i < len(feeEvents); i++ {
+		swaps = append(swaps, SwapData{Type: JUPITER, Data: feeEvents[i]})
+	}
 	return swaps
 }
 
+// processJupiterV4Swaps decodes the Jupiter v4 route instruction, v4 emits no swap events so the legs are
+// recovered from the AMMs it invokes like any other router
+func (p *Parser) processJupiterV4Swaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, route := range p.getJupiterRouteInstructions(instructionIndex, JUPITER_V4_PROGRAM_ID) {
+		swaps = append(swaps, SwapData{Type: JUPITER, Data: route})
+	}
+	return append(swaps, p.processRouterSwaps(instructionIndex)...)
+}
+
+// getJupiterRouteInstructions decodes the route instructions of the given Jupiter program executed by an outer instruction
+func (p *Parser) getJupiterRouteInstructions(instructionIndex int, programID solana.PublicKey) []*JupiterRouteInstruction {
+	var candidates []solana.CompiledInstruction
+	if instructionIndex < len(p.txInfo.Message.Instructions) {
+		candidates = append(candidates, p.txInfo.Message.Instructions[instructionIndex])
+	}
+	candidates = append(candidates, p.getInnerInstructions(instructionIndex)...)
+
+	var routes []*JupiterRouteInstruction
+	for _, candidate := range candidates {
+		if !p.allAccountKeys[candidate.ProgramIDIndex].Equals(programID) {
+			continue
+		}
+		route, err := parseJupiterRouteInstruction(programID, candidate)
+		if err != nil {
+			p.Log.Errorf("error decoding Jupiter route instruction: %s", err)
+			continue
+		}
+		if route != nil {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// parseJupiterRouteInstruction decodes a Jupiter route instruction, returning nil for other Jupiter instructions
+func parseJupiterRouteInstruction(programID solana.PublicKey, instruction solana.CompiledInstruction) (*JupiterRouteInstruction, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding instruction data: %s", err)
+	}
+	if len(decodedBytes) < 8 {
+		return nil, nil
+	}
+
+	discriminator := decodedBytes[:8]
+	route := &JupiterRouteInstruction{ProgramID: programID}
+
+	if programID.Equals(JUPITER_V4_PROGRAM_ID) {
+		// route(swap_leg, in_amount u64, minimum_out_amount u64, platform_fee_bps u8)
+		if !bytes.Equal(discriminator, JUPITER_ROUTE_DISCRIMINATOR[:]) {
+			return nil, nil
+		}
+		if len(decodedBytes) < 8+17 {
+			return nil, fmt.Errorf("route instruction data too short (%d)", len(decodedBytes))
+		}
+		tail := decodedBytes[len(decodedBytes)-17:]
+		route.Name = "route"
+		route.InAmount = binary.LittleEndian.Uint64(tail[0:8])
+		route.QuotedOutAmount = binary.LittleEndian.Uint64(tail[8:16])
+		route.PlatformFeeBps = tail[16]
+		return route, nil
+	}
+
+	switch {
+	case bytes.Equal(discriminator, JUPITER_ROUTE_DISCRIMINATOR[:]):
+		route.Name = "route"
+	case bytes.Equal(discriminator, JUPITER_SHARED_ACCOUNTS_ROUTE_DISCRIMINATOR[:]):
+		route.Name = "sharedAccountsRoute"
+	case bytes.Equal(discriminator, JUPITER_EXACT_OUT_ROUTE_DISCRIMINATOR[:]):
+		route.Name = "exactOutRoute"
+		route.ExactOut = true
+	case bytes.Equal(discriminator, JUPITER_SHARED_ACCOUNTS_EXACT_OUT_ROUTE_DISCRIMINATOR[:]):
+		route.Name = "sharedAccountsExactOutRoute"
+		route.ExactOut = true
+	case bytes.Equal(discriminator, JUPITER_ROUTE_WITH_TOKEN_LEDGER_DISCRIMINATOR[:]):
+		route.Name = "routeWithTokenLedger"
+	case bytes.Equal(discriminator, JUPITER_SHARED_ACCOUNTS_ROUTE_WITH_TOKEN_LEDGER_DISCRIMINATOR[:]):
+		route.Name = "sharedAccountsRouteWithTokenLedger"
+	default:
+		return nil, nil
+	}
+
+	tokenLedger := route.Name == "routeWithTokenLedger" || route.Name == "sharedAccountsRouteWithTokenLedger"
+	if tokenLedger {
+		// ..., quoted_out_amount u64, slippage_bps u16, platform_fee_bps u8
+		if len(decodedBytes) < 8+11 {
+			return nil, fmt.Errorf("%s instruction data too short (%d)", route.Name, len(decodedBytes))
+		}
+		tail := decodedBytes[len(decodedBytes)-11:]
+		route.QuotedOutAmount = binary.LittleEndian.Uint64(tail[0:8])
+		route.SlippageBps = binary.LittleEndian.Uint16(tail[8:10])
+		route.PlatformFeeBps = tail[10]
+		return route, nil
+	}
+
+	// ..., in_amount|out_amount u64, quoted_out_amount|quoted_in_amount u64, slippage_bps u16, platform_fee_bps u8
+	if len(decodedBytes) < 8+19 {
+		return nil, fmt.Errorf("%s instruction data too short (%d)", route.Name, len(decodedBytes))
+	}
+	tail := decodedBytes[len(decodedBytes)-19:]
+	if route.ExactOut {
+		route.OutAmount = binary.LittleEndian.Uint64(tail[0:8])
+		route.QuotedInAmount = binary.LittleEndian.Uint64(tail[8:16])
+	} else {
+		route.InAmount = binary.LittleEndian.Uint64(tail[0:8])
+		route.QuotedOutAmount = binary.LittleEndian.Uint64(tail[8:16])
+	}
+	route.SlippageBps = binary.LittleEndian.Uint16(tail[16:18])
+	route.PlatformFeeBps = tail[18]
+	return route, nil
+}
+
+func (p *Parser) parseJupiterFeeEventInstruction(instruction solana.CompiledInstruction) (*JupiterFeeEvent, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding instruction data: %s", err)
+	}
+
+	var event JupiterFeeEvent
+	if err := ag_binary.NewBorshDecoder(decodedBytes[16:]).Decode(&event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JupiterFeeEvent: %s", err)
+	}
+	return &event, nil
+}
+
 // containsDCAProgram checks if the transaction contains the Jupiter DCA program.
 func (p *Parser) containsDCAProgram() bool {
 	for _, accountKey := range p.allAccountKeys {
@@ -79,6 +263,7 @@ func (p *Parser) parseJupiterRouteEventInstruction(instruction solana.CompiledIn
 
 	return &JupiterSwapEventData{
 		JupiterSwapEvent:   *jupSwapEvent,
+		AmmName:            GetProgramLabel(jupSwapEvent.Amm),
 		InputMintDecimals:  inputMintDecimals,
 		OutputMintDecimals: outputMintDecimals,
 	}, nil
@@ -148,10 +333,14 @@ func parseJupiterEvents(events []SwapData) (*SwapInfo, error) {
 
 	var firstSwap, lastSwap *JupiterSwapEventData
 
-	for i, event := range events {
+	for _, event := range events {
 		if event.Type != JUPITER {
 			continue
 		}
+		switch event.Data.(type) {
+		case *JupiterRouteInstruction, *JupiterFeeEvent:
+			continue
+		}
 
 		var jupiterEvent JupiterSwapEventData
 		eventData, err := json.Marshal(event.Data)
@@ -163,7 +352,7 @@ func parseJupiterEvents(events []SwapData) (*SwapInfo, error) {
 			return nil, fmt.Errorf("failed to unmarshal Jupiter event data: %v", err)
 		}
 
-		if i == 0 {
+		if firstSwap == nil {
 			firstSwap = &jupiterEvent
 		}
 		lastSwap = &jupiterEvent
@@ -0,0 +1,153 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// jupiterRouteData is a route instruction with a placeholder route plan followed by its fixed-size arguments
+func jupiterRouteData(discriminator [8]byte, tail ...interface{}) []byte {
+	data := append(discriminator[:], 1, 0, 0, 0, 7, 0, 100, 0, 1)
+	for _, value := range tail {
+		switch v := value.(type) {
+		case uint64:
+			data = binary.LittleEndian.AppendUint64(data, v)
+		case uint16:
+			data = binary.LittleEndian.AppendUint16(data, v)
+		case uint8:
+			data = append(data, v)
+		}
+	}
+	return data
+}
+
+func TestJupiterRouteEvents(t *testing.T) {
+	usdc := testKey("usdc")
+	platform := testKey("platform")
+	platformFeeAccount := testKey("platform/usdc")
+
+	b := newTestTx(testTrader)
+	b.tokenAccount(testKey("trader/usdc"), testTrader, usdc, 6, 0, 149_000_000)
+	b.tokenAccount(platformFeeAccount, platform, usdc, 6, 0, 300_000)
+	b.tokenAccount(testKey("trader/mint"), testTrader, testMint, 6, 0, 0)
+	b.instruction(JUPITER_PROGRAM_ID, jupiterRouteData(JUPITER_ROUTE_DISCRIMINATOR, uint64(1_000_000_000), uint64(150_000_000), uint16(50), uint8(20)), testTrader)
+	b.invoke(0, 2, JUPITER_PROGRAM_ID, anchorEventData(t, JupiterRouteEventDiscriminator, JupiterSwapEvent{
+		Amm: ORCA_PROGRAM_ID, InputMint: solana.SolMint, InputAmount: 1_000_000_000, OutputMint: testMint, OutputAmount: 42_000_000,
+	}))
+	b.invoke(0, 2, JUPITER_PROGRAM_ID, anchorEventData(t, JupiterRouteEventDiscriminator, JupiterSwapEvent{
+		Amm: SOLFI_PROGRAM_ID, InputMint: testMint, InputAmount: 42_000_000, OutputMint: usdc, OutputAmount: 149_300_000,
+	}))
+	b.invoke(0, 2, JUPITER_PROGRAM_ID, anchorEventData(t, JupiterFeeEventDiscriminator, JupiterFeeEvent{
+		Account: platformFeeAccount, Mint: usdc, Amount: 300_000,
+	}))
+
+	_, swapDatas, swapInfo := b.parse(t)
+
+	route := singleSwap[*JupiterRouteInstruction](t, swapDatas)
+	if route.Name != "route" || route.InAmount != 1_000_000_000 || route.QuotedOutAmount != 150_000_000 || route.SlippageBps != 50 || route.PlatformFeeBps != 20 {
+		t.Errorf("route %+v, want route of 1000000000 quoted at 150000000 with 50 bps slippage and a 20 bps fee", route)
+	}
+	if route.PlatformFee == nil || route.PlatformFee.Amount != 300_000 {
+		t.Errorf("route platform fee %+v, want the fee event", route.PlatformFee)
+	}
+
+	var labels []string
+	for _, swapData := range swapDatas {
+		if hop, ok := swapData.Data.(*JupiterSwapEventData); ok {
+			labels = append(labels, hop.AmmName)
+		}
+	}
+	if len(labels) != 2 || labels[0] != "Orca Whirlpool" || labels[1] != "SolFi" {
+		t.Errorf("hop labels %v, want [Orca Whirlpool SolFi]", labels)
+	}
+
+	assertSwap(t, swapInfo, solana.SolMint, 1_000_000_000, usdc, 149_300_000)
+	if swapInfo.TokenInDecimals != 9 || swapInfo.TokenOutDecimals != 6 {
+		t.Errorf("decimals %d/%d, want 9/6", swapInfo.TokenInDecimals, swapInfo.TokenOutDecimals)
+	}
+	if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(JUPITER) {
+		t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, JUPITER)
+	}
+	if len(swapInfo.Fees) != 1 {
+		t.Fatalf("got %d fees, want 1", len(swapInfo.Fees))
+	}
+	want := Fee{Kind: FeeKindPlatform, Mint: usdc, Amount: 300_000, Recipient: platform}
+	if swapInfo.Fees[0] != want {
+		t.Errorf("fee %+v, want %+v", swapInfo.Fees[0], want)
+	}
+}
+
+func TestJupiterRouteInstructions(t *testing.T) {
+	tests := []struct {
+		name    string
+		program solana.PublicKey
+		data    []byte
+		want    JupiterRouteInstruction
+	}{
+		{
+			name:    "shared accounts route",
+			program: JUPITER_PROGRAM_ID,
+			data:    jupiterRouteData(JUPITER_SHARED_ACCOUNTS_ROUTE_DISCRIMINATOR, uint64(5_000), uint64(4_900), uint16(30), uint8(0)),
+			want:    JupiterRouteInstruction{Name: "sharedAccountsRoute", InAmount: 5_000, QuotedOutAmount: 4_900, SlippageBps: 30},
+		},
+		{
+			name:    "exact out",
+			program: JUPITER_PROGRAM_ID,
+			data:    jupiterRouteData(JUPITER_SHARED_ACCOUNTS_EXACT_OUT_ROUTE_DISCRIMINATOR, uint64(4_900), uint64(5_010), uint16(100), uint8(10)),
+			want:    JupiterRouteInstruction{Name: "sharedAccountsExactOutRoute", OutAmount: 4_900, QuotedInAmount: 5_010, SlippageBps: 100, PlatformFeeBps: 10, ExactOut: true},
+		},
+		{
+			name:    "token ledger",
+			program: JUPITER_PROGRAM_ID,
+			data:    jupiterRouteData(JUPITER_ROUTE_WITH_TOKEN_LEDGER_DISCRIMINATOR, uint64(4_900), uint16(50), uint8(0)),
+			want:    JupiterRouteInstruction{Name: "routeWithTokenLedger", QuotedOutAmount: 4_900, SlippageBps: 50},
+		},
+		{
+			name:    "v4",
+			program: JUPITER_V4_PROGRAM_ID,
+			data:    jupiterRouteData(JUPITER_ROUTE_DISCRIMINATOR, uint64(5_000), uint64(4_850), uint8(5)),
+			want:    JupiterRouteInstruction{Name: "route", InAmount: 5_000, QuotedOutAmount: 4_850, PlatformFeeBps: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := parseJupiterRouteInstruction(tt.program, solana.CompiledInstruction{Data: tt.data})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.ProgramID = tt.program
+			if route == nil || *route != tt.want {
+				t.Errorf("got %+v, want %+v", route, tt.want)
+			}
+		})
+	}
+
+	// other Jupiter instructions are not routes, routes too short for their arguments are errors
+	if route, err := parseJupiterRouteInstruction(JUPITER_PROGRAM_ID, solana.CompiledInstruction{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}}); route != nil || err != nil {
+		t.Errorf("unknown instruction decoded as %+v, %v", route, err)
+	}
+	if _, err := parseJupiterRouteInstruction(JUPITER_PROGRAM_ID, solana.CompiledInstruction{Data: JUPITER_ROUTE_DISCRIMINATOR[:]}); err == nil {
+		t.Error("truncated route decoded without error")
+	}
+}
+
+func TestJupiterV4RouteLegs(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "solfi-pool", testTrader, solana.SolMint, 9, testMint, 6)
+	b.instruction(JUPITER_V4_PROGRAM_ID, jupiterRouteData(JUPITER_ROUTE_DISCRIMINATOR, uint64(500_000_000), uint64(70_000_000), uint8(0)), testTrader)
+	b.invoke(0, 2, SOLFI_PROGRAM_ID, []byte{7}, pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB)
+	b.transfer(0, 3, pool.userA, pool.vaultA, testTrader, 500_000_000)
+	b.transfer(0, 3, pool.vaultB, pool.userB, pool.address, 71_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	route := singleSwap[*JupiterRouteInstruction](t, swapDatas)
+	if !route.ProgramID.Equals(JUPITER_V4_PROGRAM_ID) || route.InAmount != 500_000_000 || route.QuotedOutAmount != 70_000_000 {
+		t.Errorf("route %+v, want the v4 route of 500000000 for at least 70000000", route)
+	}
+	assertSwap(t, swapInfo, solana.SolMint, 500_000_000, testMint, 71_000_000)
+	if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(SOLFI) {
+		t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, SOLFI)
+	}
+}
@@ -0,0 +1,284 @@
+package solanaswapgo
+
+import (
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+// MeteoraEvtSwapDiscriminator is shared by the DAMM v2 and Dynamic Bonding Curve programs,
+// the emitting program tells the two layouts apart.
+var MeteoraEvtSwapDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 27, 60, 21, 213, 138, 170, 187, 147}
+
+var MeteoraDlmmSwapEventDiscriminator = [16]byte{228, 69, 165, 46, 81, 203, 154, 29, 81, 108, 227, 190, 205, 208, 10, 196}
+
+const (
+	MeteoraDammV2TradeDirectionAtoB = 0
+	MeteoraDammV2TradeDirectionBtoA = 1
+
+	MeteoraDbcTradeDirectionBaseToQuote = 0
+	MeteoraDbcTradeDirectionQuoteToBase = 1
+)
+
+type MeteoraSwapParameters struct {
+	AmountIn         uint64
+	MinimumAmountOut uint64
+}
+
+type MeteoraDammV2SwapResult struct {
+	OutputAmount  uint64
+	NextSqrtPrice ag_binary.Uint128
+	LpFee         uint64
+	ProtocolFee   uint64
+	PartnerFee    uint64
+	ReferralFee   uint64
+}
+
+type MeteoraDammV2SwapEvent struct {
+	Pool             solana.PublicKey
+	TradeDirection   uint8
+	HasReferral      bool
+	Params           MeteoraSwapParameters
+	SwapResult       MeteoraDammV2SwapResult
+	ActualAmountIn   uint64
+	CurrentTimestamp uint64
+}
+
+type MeteoraDammV2SwapEventData struct {
+	MeteoraDammV2SwapEvent
+	InputMint          solana.PublicKey
+	InputMintDecimals  uint8
+	OutputMint         solana.PublicKey
+	OutputMintDecimals uint8
+}
+
+type MeteoraDbcSwapResult struct {
+	ActualInputAmount uint64
+	OutputAmount      uint64
+	NextSqrtPrice     ag_binary.Uint128
+	TradingFee        uint64
+	ProtocolFee       uint64
+	ReferralFee       uint64
+}
+
+type MeteoraDbcSwapEvent struct {
+	Pool             solana.PublicKey
+	Config           solana.PublicKey
+	TradeDirection   uint8
+	HasReferral      bool
+	Params           MeteoraSwapParameters
+	SwapResult       MeteoraDbcSwapResult
+	AmountIn         uint64
+	CurrentTimestamp uint64
+}
+
+type MeteoraDbcSwapEventData struct {
+	MeteoraDbcSwapEvent
+	InputMint          solana.PublicKey
+	InputMintDecimals  uint8
+	OutputMint         solana.PublicKey
+	OutputMintDecimals uint8
+}
+
+type MeteoraDlmmSwapEvent struct {
+	LbPair      solana.PublicKey
+	From        solana.PublicKey
+	StartBinId  int32
+	EndBinId    int32
+	AmountIn    uint64
+	AmountOut   uint64
+	SwapForY    bool
+	Fee         uint64
+	ProtocolFee uint64
+	FeeBps      ag_binary.Uint128
+	HostFee     uint64
+}
+
+type MeteoraDlmmSwapEventData struct {
+	MeteoraDlmmSwapEvent
+	InputMint          solana.PublicKey
+	InputMintDecimals  uint8
+	OutputMint         solana.PublicKey
+	OutputMintDecimals uint8
+}
+
+// BinsCrossed returns the number of bins the swap moved through, the starting bin included
+func (e *MeteoraDlmmSwapEvent) BinsCrossed() int {
+	if e.EndBinId >= e.StartBinId {
+		return int(e.EndBinId-e.StartBinId) + 1
+	}
+	return int(e.StartBinId-e.EndBinId) + 1
+}
+
+// processMeteoraDlmmSwaps decodes DLMM swap events, falling back to transfers for transactions without them
+func (p *Parser) processMeteoraDlmmSwaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, innerInstruction := range p.getInnerInstructions(instructionIndex) {
+		if !p.isAnchorEventInstruction(innerInstruction, METEORA_PROGRAM_ID, MeteoraDlmmSwapEventDiscriminator) {
+			continue
+		}
+		eventData, err := p.parseMeteoraDlmmSwapEventInstruction(instructionIndex, innerInstruction)
+		if err != nil {
+			p.Log.Errorf("error processing Meteora DLMM swap event: %s", err)
+		}
+		if eventData != nil {
+			swaps = append(swaps, SwapData{Type: METEORA, Data: eventData})
+		}
+	}
+	if len(swaps) == 0 {
+		return p.processVenueTransfers(instructionIndex, METEORA, METEORA_PROGRAM_ID)
+	}
+	return swaps
+}
+
+// processMeteoraDammV2Swaps decodes DAMM v2 (cp-amm) swap events, falling back to transfers for transactions without them
+func (p *Parser) processMeteoraDammV2Swaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, innerInstruction := range p.getInnerInstructions(instructionIndex) {
+		if !p.isAnchorEventInstruction(innerInstruction, METEORA_DAMM_V2_PROGRAM_ID, MeteoraEvtSwapDiscriminator) {
+			continue
+		}
+		eventData, err := p.parseMeteoraDammV2SwapEventInstruction(instructionIndex, innerInstruction)
+		if err != nil {
+			p.Log.Errorf("error processing Meteora DAMM v2 swap event: %s", err)
+		}
+		if eventData != nil {
+			swaps = append(swaps, SwapData{Type: METEORA, Data: eventData})
+		}
+	}
+	if len(swaps) == 0 {
+		return p.processVenueTransfers(instructionIndex, METEORA, METEORA_DAMM_V2_PROGRAM_ID)
+	}
+	return swaps
+}
+
+// processMeteoraDbcSwaps decodes Dynamic Bonding Curve swap events, falling back to transfers for transactions without them
+func (p *Parser) processMeteoraDbcSwaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, innerInstruction := range p.getInnerInstructions(instructionIndex) {
+		if !p.isAnchorEventInstruction(innerInstruction, METEORA_DBC_PROGRAM_ID, MeteoraEvtSwapDiscriminator) {
+			continue
+		}
+		eventData, err := p.parseMeteoraDbcSwapEventInstruction(instructionIndex, innerInstruction)
+		if err != nil {
+			p.Log.Errorf("error processing Meteora DBC swap event: %s", err)
+		}
+		if eventData != nil {
+			swaps = append(swaps, SwapData{Type: METEORA, Data: eventData})
+		}
+	}
+	if len(swaps) == 0 {
+		return p.processVenueTransfers(instructionIndex, METEORA, METEORA_DBC_PROGRAM_ID)
+	}
+	return swaps
+}
+
+func (p *Parser) parseMeteoraDammV2SwapEventInstruction(instructionIndex int, instruction solana.CompiledInstruction) (*MeteoraDammV2SwapEventData, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding instruction data: %s", err)
+	}
+
+	var event MeteoraDammV2SwapEvent
+	if err := ag_binary.NewBorshDecoder(decodedBytes[16:]).Decode(&event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling DAMM v2 EvtSwap: %s", err)
+	}
+
+	// swap accounts: pool_authority, pool, input_token_account, output_token_account, token_a_vault, token_b_vault, token_a_mint, token_b_mint, ...
+	swapInstruction, found := p.findInstruction(instructionIndex, func(inst solana.CompiledInstruction) bool {
+		return p.allAccountKeys[inst.ProgramIDIndex].Equals(METEORA_DAMM_V2_PROGRAM_ID) &&
+			len(inst.Accounts) >= 8 &&
+			p.allAccountKeys[inst.Accounts[1]].Equals(event.Pool)
+	})
+	if !found {
+		return nil, fmt.Errorf("no DAMM v2 swap instruction found for pool %s", event.Pool)
+	}
+
+	mintA := p.allAccountKeys[swapInstruction.Accounts[6]]
+	mintB := p.allAccountKeys[swapInstruction.Accounts[7]]
+
+	eventData := &MeteoraDammV2SwapEventData{MeteoraDammV2SwapEvent: event}
+	if event.TradeDirection == MeteoraDammV2TradeDirectionAtoB {
+		eventData.InputMint, eventData.OutputMint = mintA, mintB
+	} else {
+		eventData.InputMint, eventData.OutputMint = mintB, mintA
+	}
+	eventData.InputMintDecimals = p.splDecimalsMap[eventData.InputMint.String()]
+	eventData.OutputMintDecimals = p.splDecimalsMap[eventData.OutputMint.String()]
+
+	return eventData, nil
+}
+
+func (p *Parser) parseMeteoraDbcSwapEventInstruction(instructionIndex int, instruction solana.CompiledInstruction) (*MeteoraDbcSwapEventData, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding instruction data: %s", err)
+	}
+
+	var event MeteoraDbcSwapEvent
+	if err := ag_binary.NewBorshDecoder(decodedBytes[16:]).Decode(&event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling DBC EvtSwap: %s", err)
+	}
+
+	// swap accounts: pool_authority, config, pool, input_token_account, output_token_account, base_vault, quote_vault, base_mint, quote_mint, ...
+	swapInstruction, found := p.findInstruction(instructionIndex, func(inst solana.CompiledInstruction) bool {
+		return p.allAccountKeys[inst.ProgramIDIndex].Equals(METEORA_DBC_PROGRAM_ID) &&
+			len(inst.Accounts) >= 9 &&
+			p.allAccountKeys[inst.Accounts[2]].Equals(event.Pool)
+	})
+	if !found {
+		return nil, fmt.Errorf("no DBC swap instruction found for pool %s", event.Pool)
+	}
+
+	baseMint := p.allAccountKeys[swapInstruction.Accounts[7]]
+	quoteMint := p.allAccountKeys[swapInstruction.Accounts[8]]
+
+	eventData := &MeteoraDbcSwapEventData{MeteoraDbcSwapEvent: event}
+	if event.TradeDirection == MeteoraDbcTradeDirectionBaseToQuote {
+		eventData.InputMint, eventData.OutputMint = baseMint, quoteMint
+	} else {
+		eventData.InputMint, eventData.OutputMint = quoteMint, baseMint
+	}
+	eventData.InputMintDecimals = p.splDecimalsMap[eventData.InputMint.String()]
+	eventData.OutputMintDecimals = p.splDecimalsMap[eventData.OutputMint.String()]
+
+	return eventData, nil
+}
+
+func (p *Parser) parseMeteoraDlmmSwapEventInstruction(instructionIndex int, instruction solana.CompiledInstruction) (*MeteoraDlmmSwapEventData, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error decoding instruction data: %s", err)
+	}
+
+	var event MeteoraDlmmSwapEvent
+	if err := ag_binary.NewBorshDecoder(decodedBytes[16:]).Decode(&event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling DLMM Swap event: %s", err)
+	}
+
+	// swap accounts: lb_pair, bin_array_bitmap_extension, reserve_x, reserve_y, user_token_in, user_token_out, token_x_mint, token_y_mint, ...
+	swapInstruction, found := p.findInstruction(instructionIndex, func(inst solana.CompiledInstruction) bool {
+		return p.allAccountKeys[inst.ProgramIDIndex].Equals(METEORA_PROGRAM_ID) &&
+			len(inst.Accounts) >= 8 &&
+			p.allAccountKeys[inst.Accounts[0]].Equals(event.LbPair)
+	})
+	if !found {
+		return nil, fmt.Errorf("no DLMM swap instruction found for lb pair %s", event.LbPair)
+	}
+
+	mintX := p.allAccountKeys[swapInstruction.Accounts[6]]
+	mintY := p.allAccountKeys[swapInstruction.Accounts[7]]
+
+	eventData := &MeteoraDlmmSwapEventData{MeteoraDlmmSwapEvent: event}
+	if event.SwapForY {
+		eventData.InputMint, eventData.OutputMint = mintX, mintY
+	} else {
+		eventData.InputMint, eventData.OutputMint = mintY, mintX
+	}
+	eventData.InputMintDecimals = p.splDecimalsMap[eventData.InputMint.String()]
+	eventData.OutputMintDecimals = p.splDecimalsMap[eventData.OutputMint.String()]
+
+	return eventData, nil
+}
@@ -0,0 +1,150 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+)
+
+func TestMeteoraDammV2SwapEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "damm-v2", testTrader, testMint, 6, NATIVE_SOL_MINT_PROGRAM_ID, 9)
+	authority := testKey("damm-v2/authority")
+
+	// SOL (token B) for token A
+	b.instruction(METEORA_DAMM_V2_PROGRAM_ID, anchorSwapData,
+		authority, pool.address, pool.userB, pool.userA, pool.vaultA, pool.vaultB, pool.mintA, pool.mintB, testTrader)
+	b.transfer(0, 2, pool.userB, pool.vaultB, testTrader, 1_000_000_000)
+	b.transfer(0, 2, pool.vaultA, pool.userA, authority, 150_000_000)
+	b.invoke(0, 2, METEORA_DAMM_V2_PROGRAM_ID, anchorEventData(t, MeteoraEvtSwapDiscriminator, MeteoraDammV2SwapEvent{
+		Pool:           pool.address,
+		TradeDirection: MeteoraDammV2TradeDirectionBtoA,
+		Params:         MeteoraSwapParameters{AmountIn: 1_000_000_000, MinimumAmountOut: 149_000_000},
+		SwapResult: MeteoraDammV2SwapResult{
+			OutputAmount:  150_000_000,
+			NextSqrtPrice: ag_binary.Uint128{Lo: 1 << 40},
+			LpFee:         2_000_000,
+			ProtocolFee:   500_000,
+		},
+		ActualAmountIn:   1_000_000_000,
+		CurrentTimestamp: 1717200000,
+	}), authority)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	event := singleSwap[*MeteoraDammV2SwapEventData](t, swapDatas)
+	if !event.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || event.InputMintDecimals != 9 {
+		t.Errorf("input %s (%d decimals), want SOL", event.InputMint, event.InputMintDecimals)
+	}
+	if !event.OutputMint.Equals(testMint) || event.OutputMintDecimals != 6 {
+		t.Errorf("output %s (%d decimals), want %s", event.OutputMint, event.OutputMintDecimals, testMint)
+	}
+	if event.SwapResult.LpFee != 2_000_000 || event.SwapResult.ProtocolFee != 500_000 {
+		t.Errorf("fees %d/%d, want 2000000/500000", event.SwapResult.LpFee, event.SwapResult.ProtocolFee)
+	}
+	assertSwap(t, swapInfo, NATIVE_SOL_MINT_PROGRAM_ID, 1_000_000_000, testMint, 150_000_000)
+}
+
+func TestMeteoraDbcSwapEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "dbc", testTrader, testMint, 6, NATIVE_SOL_MINT_PROGRAM_ID, 9)
+	authority, config := testKey("dbc/authority"), testKey("dbc/config")
+
+	// base token for SOL on the curve
+	b.instruction(METEORA_DBC_PROGRAM_ID, anchorSwapData,
+		authority, config, pool.address, pool.userA, pool.userB, pool.vaultA, pool.vaultB, pool.mintA, pool.mintB, testTrader)
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 5_000_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, authority, 120_000_000)
+	b.invoke(0, 2, METEORA_DBC_PROGRAM_ID, anchorEventData(t, MeteoraEvtSwapDiscriminator, MeteoraDbcSwapEvent{
+		Pool:           pool.address,
+		Config:         config,
+		TradeDirection: MeteoraDbcTradeDirectionBaseToQuote,
+		Params:         MeteoraSwapParameters{AmountIn: 5_000_000_000},
+		SwapResult: MeteoraDbcSwapResult{
+			ActualInputAmount: 5_000_000_000,
+			OutputAmount:      120_000_000,
+			TradingFee:        1_200_000,
+			ProtocolFee:       240_000,
+		},
+		AmountIn: 5_000_000_000,
+	}), authority)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	event := singleSwap[*MeteoraDbcSwapEventData](t, swapDatas)
+	if !event.Config.Equals(config) {
+		t.Errorf("config %s, want %s", event.Config, config)
+	}
+	if event.SwapResult.TradingFee != 1_200_000 {
+		t.Errorf("trading fee %d, want 1200000", event.SwapResult.TradingFee)
+	}
+	assertSwap(t, swapInfo, testMint, 5_000_000_000, NATIVE_SOL_MINT_PROGRAM_ID, 120_000_000)
+}
+
+// without the event the legs are taken from the transfers
+func TestMeteoraDammV2TransferFallback(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "damm-v2", testTrader, testMint, 6, NATIVE_SOL_MINT_PROGRAM_ID, 9)
+	authority := testKey("damm-v2/authority")
+
+	b.instruction(METEORA_DAMM_V2_PROGRAM_ID, anchorSwapData,
+		authority, pool.address, pool.userA, pool.userB, pool.vaultA, pool.vaultB, pool.mintA, pool.mintB, testTrader)
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 40_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, authority, 250_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	if len(swapDatas) != 2 {
+		t.Fatalf("got %d swap data, want the 2 transfers", len(swapDatas))
+	}
+	assertSwap(t, swapInfo, testMint, 40_000_000, NATIVE_SOL_MINT_PROGRAM_ID, 250_000_000)
+}
+
+func TestMeteoraDlmmSwapEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "dlmm", testTrader, testMint, 6, testUSDC, 6)
+	bitmap := testKey("dlmm/bitmap")
+
+	// token X for Y, crossing from bin 100 down to bin 97
+	b.instruction(METEORA_PROGRAM_ID, anchorSwapData,
+		pool.address, bitmap, pool.vaultA, pool.vaultB, pool.userA, pool.userB, pool.mintA, pool.mintB, testTrader)
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 2_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 3_100_000)
+	b.invoke(0, 2, METEORA_PROGRAM_ID, anchorEventData(t, MeteoraDlmmSwapEventDiscriminator, MeteoraDlmmSwapEvent{
+		LbPair:      pool.address,
+		From:        testTrader,
+		StartBinId:  100,
+		EndBinId:    97,
+		AmountIn:    2_000_000,
+		AmountOut:   3_100_000,
+		SwapForY:    true,
+		Fee:         5_000,
+		ProtocolFee: 250,
+		FeeBps:      ag_binary.Uint128{Lo: 25},
+	}), pool.address)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	event := singleSwap[*MeteoraDlmmSwapEventData](t, swapDatas)
+	if got := event.BinsCrossed(); got != 4 {
+		t.Errorf("bins crossed %d, want 4", got)
+	}
+	if event.Fee != 5_000 || event.ProtocolFee != 250 || event.FeeBps.Lo != 25 {
+		t.Errorf("fee %d, protocol fee %d, fee bps %d", event.Fee, event.ProtocolFee, event.FeeBps.Lo)
+	}
+	assertSwap(t, swapInfo, testMint, 2_000_000, testUSDC, 3_100_000)
+}
+
+func TestMeteoraDlmmBinsCrossed(t *testing.T) {
+	tests := []struct {
+		start, end int32
+		want       int
+	}{
+		{10, 10, 1},
+		{10, 12, 3},
+		{-3, -7, 5},
+		{-1, 1, 3},
+	}
+	for _, tt := range tests {
+		event := MeteoraDlmmSwapEvent{StartBinId: tt.start, EndBinId: tt.end}
+		if got := event.BinsCrossed(); got != tt.want {
+			t.Errorf("bins %d to %d: got %d, want %d", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package solanaswapgo
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/mr-tron/base58"
 )
@@ -69,99 +68,14 @@ func (p *Parser) processOKXSwaps(instructionIndex int) []SwapData {
 }
 
 func (p *Parser) processOKXRouterSwaps(instructionIndex int) []SwapData {
-	var swaps []SwapData
-	seen := make(map[string]bool)
-	processedProtocols := make(map[SwapType]bool)
-
 	innerInstructions := p.getInnerInstructions(instructionIndex)
 	p.Log.Infof("processing okx router swaps for instruction %d: %d inner instructions", instructionIndex, len(innerInstructions))
 	if len(innerInstructions) == 0 {
 		p.Log.Warnf("no inner instructions for instruction %d", instructionIndex)
-		return swaps
-	}
-
-	for _, inner := range innerInstructions {
-		progID := p.allAccountKeys[inner.ProgramIDIndex]
-
-		switch {
-		case progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
-			if processedProtocols[RAYDIUM] {
-				continue
-			}
-			if raydSwaps := p.processRaydSwaps(instructionIndex); len(raydSwaps) > 0 {
-				for _, swap := range raydSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[RAYDIUM] = true
-			}
-
-		case progID.Equals(ORCA_PROGRAM_ID):
-			if processedProtocols[ORCA] {
-				continue
-			}
-			if orcaSwaps := p.processOrcaSwaps(instructionIndex); len(orcaSwaps) > 0 {
-				for _, swap := range orcaSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[ORCA] = true
-			}
-
-		case progID.Equals(METEORA_PROGRAM_ID) ||
-			progID.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			progID.Equals(METEORA_DLMM_PROGRAM_ID):
-			if processedProtocols[METEORA] {
-				continue
-			}
-			if meteoraSwaps := p.processMeteoraSwaps(instructionIndex); len(meteoraSwaps) > 0 {
-				for _, swap := range meteoraSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[METEORA] = true
-			}
-
-		case progID.Equals(PUMP_FUN_PROGRAM_ID):
-			if processedProtocols[PUMP_FUN] {
-				continue
-			}
-			if pumpfunSwaps := p.processPumpfunSwaps(instructionIndex); len(pumpfunSwaps) > 0 {
-				for _, swap := range pumpfunSwaps {
-					key := getSwapKey(swap)
-					if !seen[key] {
-						swaps = append(swaps, swap)
-						seen[key] = true
-					}
-				}
-				processedProtocols[PUMP_FUN] = true
-			}
-		}
+		return nil
 	}
 
-	p.Log.Infof("processed okx router swaps: %d unique swaps", len(swaps))
+	swaps := p.processRouterSwaps(instructionIndex)
+	p.Log.Infof("processed okx router swaps: %d swaps", len(swaps))
 	return swaps
 }
-
-func getSwapKey(swap SwapData) string {
-	switch data := swap.Data.(type) {
-	case *TransferCheck:
-		return fmt.Sprintf("%s-%s-%s", swap.Type, data.Info.TokenAmount.Amount, data.Info.Mint)
-	case *TransferData:
-		return fmt.Sprintf("%s-%d-%s", swap.Type, data.Info.Amount, data.Mint)
-	default:
-		return fmt.Sprintf("%s-%v", swap.Type, data)
-	}
-}
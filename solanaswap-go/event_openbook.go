@@ -0,0 +1,294 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	OPENBOOK_PLACE_TAKE_ORDER_DISCRIMINATOR = [8]byte{3, 44, 71, 3, 26, 199, 203, 85}
+	OPENBOOK_PLACE_ORDER_DISCRIMINATOR      = [8]byte{51, 194, 155, 175, 109, 130, 96, 106}
+
+	OpenbookFillLogDiscriminator             = [8]byte{150, 23, 41, 148, 152, 162, 215, 64}
+	OpenbookTotalOrderFillEventDiscriminator = [8]byte{8, 235, 48, 58, 174, 76, 156, 105}
+)
+
+const (
+	SERUM_SETTLE_FUNDS_INSTRUCTION = 5
+	SERUM_NEW_ORDER_V3_INSTRUCTION = 10
+)
+
+type OpenbookFillLog struct {
+	Market             solana.PublicKey
+	TakerSide          uint8
+	MakerSlot          uint8
+	MakerOut           bool
+	Timestamp          uint64
+	SeqNum             uint64
+	Maker              solana.PublicKey
+	MakerClientOrderId uint64
+	MakerFee           uint64
+	MakerTimestamp     uint64
+	Taker              solana.PublicKey
+	TakerClientOrderId uint64
+	TakerFeeCeil       uint64
+	Price              int64
+	Quantity           int64
+}
+
+type OpenbookTotalOrderFillEvent struct {
+	Side                  uint8
+	Taker                 solana.PublicKey
+	TotalQuantityPaid     uint64
+	TotalQuantityReceived uint64
+	Fees                  uint64
+}
+
+// OpenbookTradeData is an order-book order decoded from OpenBook v2 placeTakeOrder/placeOrder or a Serum/OpenBook v1
+// newOrderV3 instruction, prices are in quote lots per base lot and quantities in base lots as sent to the program,
+// the native amounts are what the trader actually paid and received (zero when the order rests or settles later)
+type OpenbookTradeData struct {
+	Program       solana.PublicKey
+	Instruction   string
+	Market        solana.PublicKey
+	Side          TradeType
+	PriceLots     int64
+	MaxBaseLots   int64
+	BaseMint      solana.PublicKey
+	BaseDecimals  uint8
+	QuoteMint     solana.PublicKey
+	QuoteDecimals uint8
+	BaseAmount    uint64
+	QuoteAmount   uint64
+	Fees          uint64
+	Fills         []OpenbookFillLog
+
+	// ViaAmm is set when the order was placed by an AMM (Raydium V4) on its backing market rather than by the trader
+	ViaAmm solana.PublicKey
+}
+
+// processOpenbookV2Trades decodes OpenBook v2 placeTakeOrder and placeOrder instructions, each with the fill logs
+// emitted by its own invocation
+func (p *Parser) processOpenbookV2Trades(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, candidate := range p.getInstructionNodes(instructionIndex) {
+		if !candidate.ProgramID.Equals(OPENBOOK_V2_PROGRAM_ID) {
+			continue
+		}
+		trade, err := p.parseOpenbookV2OrderInstruction(candidate.Instruction)
+		if err != nil {
+			p.Log.Errorf("error decoding OpenBook v2 order instruction: %s", err)
+			continue
+		}
+		if trade == nil {
+			continue
+		}
+
+		for _, logData := range p.getInvocationLogs(candidate) {
+			if len(logData) < 8 {
+				continue
+			}
+			decoder := ag_binary.NewBorshDecoder(logData[8:])
+			switch {
+			case bytes.Equal(logData[:8], OpenbookFillLogDiscriminator[:]):
+				var fill OpenbookFillLog
+				if err := decoder.Decode(&fill); err != nil {
+					p.Log.Errorf("error processing OpenBook FillLog: %s", err)
+					continue
+				}
+				if fill.Market.Equals(trade.Market) {
+					trade.Fills = append(trade.Fills, fill)
+				}
+			case bytes.Equal(logData[:8], OpenbookTotalOrderFillEventDiscriminator[:]):
+				var total OpenbookTotalOrderFillEvent
+				if err := decoder.Decode(&total); err != nil {
+					p.Log.Errorf("error processing OpenBook TotalOrderFillEvent: %s", err)
+					continue
+				}
+				trade.Fees = total.Fees
+				if trade.Side == TradeTypeBuy {
+					trade.QuoteAmount, trade.BaseAmount = total.TotalQuantityPaid, total.TotalQuantityReceived
+				} else {
+					trade.BaseAmount, trade.QuoteAmount = total.TotalQuantityPaid, total.TotalQuantityReceived
+				}
+			}
+		}
+
+		swaps = append(swaps, SwapData{Type: OPENBOOK, Data: trade})
+	}
+	return swaps
+}
+
+func (p *Parser) parseOpenbookV2OrderInstruction(instruction solana.CompiledInstruction) (*OpenbookTradeData, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base58 instruction data: %v", err)
+	}
+	// args: side u8, price_lots i64, max_base_lots i64, ...
+	if len(decodedBytes) < 25 {
+		return nil, nil
+	}
+
+	account := func(i int) solana.PublicKey {
+		return p.allAccountKeys[instruction.Accounts[i]]
+	}
+	trade := &OpenbookTradeData{
+		Program:     OPENBOOK_V2_PROGRAM_ID,
+		Side:        TradeType(decodedBytes[8]),
+		PriceLots:   int64(binary.LittleEndian.Uint64(decodedBytes[9:17])),
+		MaxBaseLots: int64(binary.LittleEndian.Uint64(decodedBytes[17:25])),
+	}
+
+	switch {
+	case bytes.Equal(decodedBytes[:8], OPENBOOK_PLACE_TAKE_ORDER_DISCRIMINATOR[:]):
+		// accounts: signer, penalty_payer, market, market_authority, bids, asks, market_base_vault, market_quote_vault, ...
+		if len(instruction.Accounts) < 8 {
+			return nil, fmt.Errorf("placeTakeOrder instruction has %d accounts", len(instruction.Accounts))
+		}
+		trade.Instruction = "placeTakeOrder"
+		trade.Market = account(2)
+		trade.BaseMint, trade.BaseDecimals = p.tokenAccountMint(account(6))
+		trade.QuoteMint, trade.QuoteDecimals = p.tokenAccountMint(account(7))
+
+	case bytes.Equal(decodedBytes[:8], OPENBOOK_PLACE_ORDER_DISCRIMINATOR[:]):
+		// accounts: signer, open_orders_account, open_orders_admin, user_token_account, market, bids, asks, event_heap, market_vault, ...
+		if len(instruction.Accounts) < 9 {
+			return nil, fmt.Errorf("placeOrder instruction has %d accounts", len(instruction.Accounts))
+		}
+		trade.Instruction = "placeOrder"
+		trade.Market = account(4)
+		if trade.Side == TradeTypeBuy {
+			trade.QuoteMint, trade.QuoteDecimals = p.tokenAccountMint(account(8))
+		} else {
+			trade.BaseMint, trade.BaseDecimals = p.tokenAccountMint(account(8))
+		}
+
+	default:
+		return nil, nil
+	}
+
+	return trade, nil
+}
+
+// processOpenbookV1Trades decodes Serum/OpenBook v1 newOrderV3 instructions, the native amounts are taken from the
+// transfers into the market vaults made by the order and the transfers out of them made by the settleFunds of its
+// open orders account that follows it. Without stack heights the order's transfers are those of its whole outer
+// instruction. viaAmm is the AMM program placing the orders on its backing market, or the zero key for orders placed
+// by the trader
+func (p *Parser) processOpenbookV1Trades(instructionIndex int, viaAmm solana.PublicKey) []SwapData {
+	var swaps []SwapData
+	for _, node := range p.getInstructionNodes(instructionIndex) {
+		progID := node.ProgramID
+		if !progID.Equals(OPENBOOK_V1_PROGRAM_ID) && !progID.Equals(SERUM_V3_PROGRAM_ID) {
+			continue
+		}
+		candidate := node.Instruction
+		decodedBytes, err := base58.Decode(candidate.Data.String())
+		if err != nil {
+			p.Log.Errorf("error decoding Serum instruction: %s", err)
+			continue
+		}
+		// data: version u8, tag u32, side u32, limit_price u64, max_coin_qty u64, max_native_pc_qty_including_fees u64, ...
+		if len(decodedBytes) < 51 || binary.LittleEndian.Uint32(decodedBytes[1:5]) != SERUM_NEW_ORDER_V3_INSTRUCTION {
+			continue
+		}
+		// accounts: market, open_orders, request_queue, event_queue, bids, asks, order_payer, open_orders_owner, coin_vault, pc_vault, ...
+		if len(candidate.Accounts) < 10 {
+			p.Log.Warnf("Serum newOrderV3 instruction has %d accounts", len(candidate.Accounts))
+			continue
+		}
+
+		coinVault := p.allAccountKeys[candidate.Accounts[8]].String()
+		pcVault := p.allAccountKeys[candidate.Accounts[9]].String()
+		trade := &OpenbookTradeData{
+			Program:     progID,
+			Instruction: "newOrderV3",
+			Market:      p.allAccountKeys[candidate.Accounts[0]],
+			Side:        TradeType(binary.LittleEndian.Uint32(decodedBytes[5:9])),
+			PriceLots:   int64(binary.LittleEndian.Uint64(decodedBytes[9:17])),
+			MaxBaseLots: int64(binary.LittleEndian.Uint64(decodedBytes[17:25])),
+			ViaAmm:      viaAmm,
+		}
+		trade.BaseMint, trade.BaseDecimals = p.tokenAccountMint(p.allAccountKeys[candidate.Accounts[8]])
+		trade.QuoteMint, trade.QuoteDecimals = p.tokenAccountMint(p.allAccountKeys[candidate.Accounts[9]])
+
+		transfers := node.descendants()
+		if !p.hasStackHeights(node.OuterIndex) {
+			transfers = p.GetCallTree()[node.OuterIndex].descendants()
+		}
+		if settle := p.getSerumSettle(node); settle != nil {
+			transfers = append(transfers, settle.descendants()...)
+		}
+		for _, transferNode := range transfers {
+			if !p.isTransfer(transferNode.Instruction) {
+				continue
+			}
+			transfer := p.processTransfer(transferNode.Instruction)
+			switch {
+			case transfer.Info.Destination == coinVault && trade.Side == TradeTypeSell,
+				transfer.Info.Source == coinVault && trade.Side == TradeTypeBuy:
+				trade.BaseAmount += transfer.Info.Amount
+			case transfer.Info.Destination == pcVault && trade.Side == TradeTypeBuy,
+				transfer.Info.Source == pcVault && trade.Side == TradeTypeSell:
+				trade.QuoteAmount += transfer.Info.Amount
+			}
+		}
+
+		swapType := SERUM
+		if progID.Equals(OPENBOOK_V1_PROGRAM_ID) {
+			swapType = OPENBOOK
+		}
+		swaps = append(swaps, SwapData{Type: swapType, Data: trade})
+	}
+	return swaps
+}
+
+// getSerumSettle returns the settleFunds instruction of an order's market and open orders account that follows the
+// order, or nil when the proceeds are left unsettled or another order on the account comes first
+func (p *Parser) getSerumSettle(order *InstructionNode) *InstructionNode {
+	after := false
+	for _, root := range p.GetCallTree() {
+		for _, node := range append([]*InstructionNode{root}, root.descendants()...) {
+			if node == order {
+				after = true
+				continue
+			}
+			accounts := node.Instruction.Accounts
+			if !after || !node.ProgramID.Equals(order.ProgramID) || len(accounts) < 2 {
+				continue
+			}
+			if accounts[0] != order.Instruction.Accounts[0] || accounts[1] != order.Instruction.Accounts[1] {
+				continue
+			}
+			decodedBytes, err := base58.Decode(node.Instruction.Data.String())
+			if err != nil || len(decodedBytes) < 5 {
+				continue
+			}
+			switch binary.LittleEndian.Uint32(decodedBytes[1:5]) {
+			case SERUM_SETTLE_FUNDS_INSTRUCTION:
+				return node
+			case SERUM_NEW_ORDER_V3_INSTRUCTION:
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// tokenAccountMint returns the mint and decimals of a token account seen in the transaction
+func (p *Parser) tokenAccountMint(account solana.PublicKey) (solana.PublicKey, uint8) {
+	info, ok := p.splTokenInfoMap[account.String()]
+	if !ok {
+		return solana.PublicKey{}, 0
+	}
+	mint, err := solana.PublicKeyFromBase58(info.Mint)
+	if err != nil {
+		return solana.PublicKey{}, 0
+	}
+	return mint, info.Decimals
+}
@@ -0,0 +1,176 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func openbookV2TakeOrderData(side TradeType, priceLots, maxBaseLots int64) []byte {
+	data := append(OPENBOOK_PLACE_TAKE_ORDER_DISCRIMINATOR[:], byte(side))
+	data = binary.LittleEndian.AppendUint64(data, uint64(priceLots))
+	return binary.LittleEndian.AppendUint64(data, uint64(maxBaseLots))
+}
+
+func TestOpenbookV2TakeOrder(t *testing.T) {
+	b := newTestTx(testTrader)
+	market := newTestPool(b, "openbook-market", testTrader, testMint, 6, testUSDC, 6)
+	b.instruction(OPENBOOK_V2_PROGRAM_ID, openbookV2TakeOrderData(TradeTypeSell, 40, 7),
+		testTrader, testTrader, market.address, testKey("market-authority"), testKey("bids"), testKey("asks"), market.vaultA, market.vaultB).
+		emit(anchorLogData(t, OpenbookFillLogDiscriminator, OpenbookFillLog{Market: market.address, Taker: testTrader, Price: 40, Quantity: 4})).
+		emit(anchorLogData(t, OpenbookFillLogDiscriminator, OpenbookFillLog{Market: market.address, Taker: testTrader, Price: 41, Quantity: 3})).
+		emit(anchorLogData(t, OpenbookTotalOrderFillEventDiscriminator, OpenbookTotalOrderFillEvent{
+			Side: uint8(TradeTypeSell), Taker: testTrader, TotalQuantityPaid: 7_000_000, TotalQuantityReceived: 283_000_000, Fees: 56_600,
+		}))
+
+	_, swapDatas, swapInfo := b.parse(t)
+	trade := singleSwap[*OpenbookTradeData](t, swapDatas)
+	if trade.Instruction != "placeTakeOrder" || trade.Side != TradeTypeSell || trade.PriceLots != 40 || trade.MaxBaseLots != 7 {
+		t.Errorf("trade %s side %d at %d for %d, want placeTakeOrder sell at 40 for 7", trade.Instruction, trade.Side, trade.PriceLots, trade.MaxBaseLots)
+	}
+	if len(trade.Fills) != 2 || trade.Fees != 56_600 {
+		t.Errorf("%d fills, fees %d, want 2 fills and 56600", len(trade.Fills), trade.Fees)
+	}
+	assertSwap(t, swapInfo, testMint, 7_000_000, testUSDC, 283_000_000)
+}
+
+func TestOpenbookV2FillsPerInvocation(t *testing.T) {
+	quoteMint := testKey("usdc")
+	b := newTestTx(testTrader)
+	first := newTestPool(b, "openbook-market-1", testTrader, testMint, 6, quoteMint, 6)
+	second := newTestPool(b, "openbook-market-2", testTrader, testKey("other-mint"), 9, quoteMint, 6)
+	takeOrder := func(market testPool) []solana.PublicKey {
+		return []solana.PublicKey{testTrader, testTrader, market.address, testKey("market-authority"), testKey("bids"), testKey("asks"), market.vaultA, market.vaultB}
+	}
+
+	// a router takes from two books in one instruction, each invocation logs its own fills
+	b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+	b.invoke(0, 2, OPENBOOK_V2_PROGRAM_ID, openbookV2TakeOrderData(TradeTypeBuy, 50, 10), takeOrder(first)...).
+		emit(anchorLogData(t, OpenbookFillLogDiscriminator, OpenbookFillLog{Market: first.address, Taker: testTrader, Price: 50, Quantity: 10})).
+		emit(anchorLogData(t, OpenbookFillLogDiscriminator, OpenbookFillLog{Market: second.address, Taker: testTrader, Price: 1, Quantity: 1})).
+		emit(anchorLogData(t, OpenbookTotalOrderFillEventDiscriminator, OpenbookTotalOrderFillEvent{
+			Side: uint8(TradeTypeBuy), Taker: testTrader, TotalQuantityPaid: 500, TotalQuantityReceived: 10, Fees: 2,
+		}))
+	b.invoke(0, 2, OPENBOOK_V2_PROGRAM_ID, openbookV2TakeOrderData(TradeTypeSell, 40, 7), takeOrder(second)...).
+		emit(anchorLogData(t, OpenbookFillLogDiscriminator, OpenbookFillLog{Market: second.address, Taker: testTrader, Price: 40, Quantity: 7})).
+		emit(anchorLogData(t, OpenbookTotalOrderFillEventDiscriminator, OpenbookTotalOrderFillEvent{
+			Side: uint8(TradeTypeSell), Taker: testTrader, TotalQuantityPaid: 7, TotalQuantityReceived: 300, Fees: 1,
+		}))
+
+	parser := b.parser(t)
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapDatas) != 2 {
+		t.Fatalf("got %d swap data, want 2", len(swapDatas))
+	}
+
+	want := []OpenbookTradeData{
+		{Market: first.address, Side: TradeTypeBuy, BaseMint: testMint, QuoteMint: quoteMint, BaseAmount: 10, QuoteAmount: 500, Fees: 2},
+		{Market: second.address, Side: TradeTypeSell, BaseMint: second.mintA, QuoteMint: quoteMint, BaseAmount: 7, QuoteAmount: 300, Fees: 1},
+	}
+	for i, swapData := range swapDatas {
+		trade, ok := swapData.Data.(*OpenbookTradeData)
+		if !ok || swapData.Type != OPENBOOK {
+			t.Fatalf("swap data %d is %s %T, want an OpenBook trade", i, swapData.Type, swapData.Data)
+		}
+		if trade.Instruction != "placeTakeOrder" || !trade.Market.Equals(want[i].Market) || trade.Side != want[i].Side {
+			t.Errorf("trade %d is %s %d on %s, want placeTakeOrder %d on %s", i, trade.Instruction, trade.Side, trade.Market, want[i].Side, want[i].Market)
+		}
+		if !trade.BaseMint.Equals(want[i].BaseMint) || !trade.QuoteMint.Equals(want[i].QuoteMint) {
+			t.Errorf("trade %d mints %s/%s, want %s/%s", i, trade.BaseMint, trade.QuoteMint, want[i].BaseMint, want[i].QuoteMint)
+		}
+		if trade.BaseAmount != want[i].BaseAmount || trade.QuoteAmount != want[i].QuoteAmount || trade.Fees != want[i].Fees {
+			t.Errorf("trade %d base %d quote %d fees %d, want %d %d %d", i, trade.BaseAmount, trade.QuoteAmount, trade.Fees,
+				want[i].BaseAmount, want[i].QuoteAmount, want[i].Fees)
+		}
+		if len(trade.Fills) != 1 || !trade.Fills[0].Market.Equals(want[i].Market) {
+			t.Errorf("trade %d has fills %+v, want one on its market", i, trade.Fills)
+		}
+	}
+}
+
+func serumInstructionData(tag uint32, values ...uint64) []byte {
+	data := binary.LittleEndian.AppendUint32([]byte{0}, tag)
+	for _, value := range values {
+		data = binary.LittleEndian.AppendUint64(data, value)
+	}
+	return data
+}
+
+// serumNewOrderData is a newOrderV3 with its self trade behavior, order type, client id and limit left zero
+func serumNewOrderData(side TradeType, limitPrice, maxCoinQty, maxNativePcQty uint64) []byte {
+	data := binary.LittleEndian.AppendUint32(serumInstructionData(SERUM_NEW_ORDER_V3_INSTRUCTION), uint32(side))
+	for _, value := range []uint64{limitPrice, maxCoinQty, maxNativePcQty} {
+		data = binary.LittleEndian.AppendUint64(data, value)
+	}
+	return append(data, make([]byte, 51-len(data))...)
+}
+
+func TestSerumNewOrderSettle(t *testing.T) {
+	quoteMint := testKey("usdc")
+	openOrders := testKey("open-orders")
+	vaultSigner := testKey("vault-signer")
+
+	newTx := func() (*testTx, testPool, func(openOrders solana.PublicKey) []solana.PublicKey) {
+		b := newTestTx(testTrader)
+		market := newTestPool(b, "serum-market", testTrader, testMint, 6, quoteMint, 6)
+		settleAccounts := func(openOrders solana.PublicKey) []solana.PublicKey {
+			return []solana.PublicKey{market.address, openOrders, testTrader, market.vaultA, market.vaultB, market.userA, market.userB, vaultSigner}
+		}
+		return b, market, settleAccounts
+	}
+	newOrderAccounts := func(market testPool) []solana.PublicKey {
+		return []solana.PublicKey{market.address, openOrders, testKey("request-queue"), testKey("event-queue"), testKey("bids"), testKey("asks"),
+			market.userB, testTrader, market.vaultA, market.vaultB}
+	}
+
+	t.Run("settled", func(t *testing.T) {
+		b, market, settleAccounts := newTx()
+		b.instruction(SERUM_V3_PROGRAM_ID, serumNewOrderData(TradeTypeBuy, 25, 40, 1_000), newOrderAccounts(market)...)
+		b.transfer(0, 2, market.userB, market.vaultB, testTrader, 1_000)
+		// another trader's settlement out of the same vault is not part of the order
+		b.instruction(SERUM_V3_PROGRAM_ID, serumInstructionData(SERUM_SETTLE_FUNDS_INSTRUCTION), settleAccounts(testKey("other-open-orders"))...)
+		b.transfer(1, 2, market.vaultA, testKey("other-trader"), vaultSigner, 99)
+		b.instruction(SERUM_V3_PROGRAM_ID, serumInstructionData(SERUM_SETTLE_FUNDS_INSTRUCTION), settleAccounts(openOrders)...)
+		b.transfer(2, 2, market.vaultA, market.userA, vaultSigner, 40)
+
+		_, swapDatas, swapInfo := b.parse(t)
+		trade := singleSwap[*OpenbookTradeData](t, swapDatas)
+		if trade.Instruction != "newOrderV3" || !trade.Program.Equals(SERUM_V3_PROGRAM_ID) || trade.PriceLots != 25 || trade.MaxBaseLots != 40 {
+			t.Errorf("trade %s on %s at %d for %d, want newOrderV3 on Serum at 25 for 40", trade.Instruction, trade.Program, trade.PriceLots, trade.MaxBaseLots)
+		}
+		if trade.BaseAmount != 40 || trade.QuoteAmount != 1_000 {
+			t.Errorf("trade base %d quote %d, want 40 1000", trade.BaseAmount, trade.QuoteAmount)
+		}
+		assertSwap(t, swapInfo, quoteMint, 1_000, testMint, 40)
+	})
+
+	t.Run("unsettled", func(t *testing.T) {
+		b, market, settleAccounts := newTx()
+		b.instruction(SERUM_V3_PROGRAM_ID, serumNewOrderData(TradeTypeBuy, 25, 40, 1_000), newOrderAccounts(market)...)
+		b.transfer(0, 2, market.userB, market.vaultB, testTrader, 1_000)
+		// a second order on the account takes the settlement that follows it
+		b.instruction(SERUM_V3_PROGRAM_ID, serumNewOrderData(TradeTypeBuy, 26, 10, 260), newOrderAccounts(market)...)
+		b.transfer(1, 2, market.userB, market.vaultB, testTrader, 260)
+		b.instruction(SERUM_V3_PROGRAM_ID, serumInstructionData(SERUM_SETTLE_FUNDS_INSTRUCTION), settleAccounts(openOrders)...)
+		b.transfer(2, 2, market.vaultA, market.userA, vaultSigner, 10)
+
+		parser := b.parser(t)
+		swapDatas, err := parser.ParseTransaction()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(swapDatas) != 2 {
+			t.Fatalf("got %d swap data, want 2", len(swapDatas))
+		}
+		for i, want := range [][2]uint64{{0, 1_000}, {10, 260}} {
+			trade := swapDatas[i].Data.(*OpenbookTradeData)
+			if trade.BaseAmount != want[0] || trade.QuoteAmount != want[1] {
+				t.Errorf("order %d base %d quote %d, want %d %d", i, trade.BaseAmount, trade.QuoteAmount, want[0], want[1])
+			}
+		}
+	})
+}
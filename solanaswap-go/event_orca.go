@@ -0,0 +1,264 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	ORCA_SWAP_DISCRIMINATOR            = [8]byte{248, 198, 158, 145, 225, 117, 135, 200}
+	ORCA_SWAP_V2_DISCRIMINATOR         = [8]byte{43, 4, 237, 11, 26, 201, 30, 98}
+	ORCA_TWO_HOP_SWAP_DISCRIMINATOR    = [8]byte{195, 96, 237, 108, 68, 162, 219, 230}
+	ORCA_TWO_HOP_SWAP_V2_DISCRIMINATOR = [8]byte{186, 143, 209, 29, 254, 2, 194, 117}
+
+	OrcaTradedEventDiscriminator = [8]byte{225, 202, 73, 175, 147, 43, 160, 150}
+)
+
+type OrcaSwapArgs struct {
+	Amount                 uint64
+	OtherAmountThreshold   uint64
+	SqrtPriceLimit         ag_binary.Uint128
+	AmountSpecifiedIsInput bool
+	AToB                   bool
+}
+
+type OrcaTwoHopSwapArgs struct {
+	Amount                 uint64
+	OtherAmountThreshold   uint64
+	AmountSpecifiedIsInput bool
+	AToBOne                bool
+	AToBTwo                bool
+	SqrtPriceLimitOne      ag_binary.Uint128
+	SqrtPriceLimitTwo      ag_binary.Uint128
+}
+
+// OrcaSwapInstruction is a decoded Whirlpool swap, swapV2, twoHopSwap or twoHopSwapV2 instruction,
+// two-hop swaps list both whirlpools and directions in route order
+type OrcaSwapInstruction struct {
+	Name                   string
+	Whirlpools             []solana.PublicKey
+	AToB                   []bool
+	Amount                 uint64
+	OtherAmountThreshold   uint64
+	AmountSpecifiedIsInput bool
+
+	// token mints (a, b) of each whirlpool, in the same order as Whirlpools
+	mints [][2]solana.PublicKey
+}
+
+type OrcaTradedEvent struct {
+	Whirlpool         solana.PublicKey
+	AToB              bool
+	PreSqrtPrice      ag_binary.Uint128
+	PostSqrtPrice     ag_binary.Uint128
+	InputAmount       uint64
+	OutputAmount      uint64
+	InputTransferFee  uint64
+	OutputTransferFee uint64
+	LpFee             uint64
+	ProtocolFee       uint64
+}
+
+type OrcaTradedEventData struct {
+	OrcaTradedEvent
+	InputMint          solana.PublicKey
+	InputMintDecimals  uint8
+	OutputMint         solana.PublicKey
+	OutputMintDecimals uint8
+}
+
+// processOrcaSwaps decodes Whirlpool Traded events, transactions predating the event fall back to
+// the decoded swap instructions plus their token transfers
+func (p *Parser) processOrcaSwaps(instructionIndex int) []SwapData {
+	instructions := p.getOrcaSwapInstructions(instructionIndex)
+
+	poolMints := make(map[solana.PublicKey][2]solana.PublicKey)
+	for _, instruction := range instructions {
+		for i, whirlpool := range instruction.Whirlpools {
+			poolMints[whirlpool] = instruction.mints[i]
+		}
+	}
+
+	var swaps []SwapData
+	for _, logData := range p.getProgramDataLogs(instructionIndex, ORCA_PROGRAM_ID) {
+		if len(logData) < 8 || !bytes.Equal(logData[:8], OrcaTradedEventDiscriminator[:]) {
+			continue
+		}
+		var event OrcaTradedEvent
+		if err := ag_binary.NewBorshDecoder(logData[8:]).Decode(&event); err != nil {
+			p.Log.Errorf("error processing Orca Traded event: %s", err)
+			continue
+		}
+
+		mints, ok := poolMints[event.Whirlpool]
+		if !ok {
+			p.Log.Warnf("no Orca swap instruction found for whirlpool %s", event.Whirlpool)
+			continue
+		}
+
+		eventData := &OrcaTradedEventData{OrcaTradedEvent: event}
+		if event.AToB {
+			eventData.InputMint, eventData.OutputMint = mints[0], mints[1]
+		} else {
+			eventData.InputMint, eventData.OutputMint = mints[1], mints[0]
+		}
+		eventData.InputMintDecimals = p.splDecimalsMap[eventData.InputMint.String()]
+		eventData.OutputMintDecimals = p.splDecimalsMap[eventData.OutputMint.String()]
+
+		swaps = append(swaps, SwapData{Type: ORCA, Data: eventData})
+	}
+	if len(swaps) > 0 {
+		return swaps
+	}
+
+	for _, instruction := range instructions {
+		swaps = append(swaps, SwapData{Type: ORCA, Data: instruction})
+	}
+	return append(swaps, p.processOrcaTransfers(instructionIndex)...)
+}
+
+// getOrcaSwapInstructions decodes every Whirlpool swap instruction executed by the given outer instruction
+func (p *Parser) getOrcaSwapInstructions(instructionIndex int) []*OrcaSwapInstruction {
+	var candidates []solana.CompiledInstruction
+	if instructionIndex < len(p.txInfo.Message.Instructions) {
+		candidates = append(candidates, p.txInfo.Message.Instructions[instructionIndex])
+	}
+	candidates = append(candidates, p.getInnerInstructions(instructionIndex)...)
+
+	var instructions []*OrcaSwapInstruction
+	for _, candidate := range candidates {
+		if !p.allAccountKeys[candidate.ProgramIDIndex].Equals(ORCA_PROGRAM_ID) {
+			continue
+		}
+		instruction, err := p.parseOrcaSwapInstruction(candidate)
+		if err != nil {
+			p.Log.Errorf("error decoding Orca swap instruction: %s", err)
+			continue
+		}
+		if instruction != nil {
+			instructions = append(instructions, instruction)
+		}
+	}
+	return instructions
+}
+
+// parseOrcaSwapInstruction decodes a Whirlpool swap instruction, returning nil for other Whirlpool instructions
+func (p *Parser) parseOrcaSwapInstruction(instruction solana.CompiledInstruction) (*OrcaSwapInstruction, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base58 instruction data: %v", err)
+	}
+	if len(decodedBytes) < 8 {
+		return nil, nil
+	}
+
+	discriminator := decodedBytes[:8]
+	decoder := ag_binary.NewBorshDecoder(decodedBytes[8:])
+	account := func(i int) solana.PublicKey {
+		return p.allAccountKeys[instruction.Accounts[i]]
+	}
+	vaultMint := func(i int) solana.PublicKey {
+		mint, err := solana.PublicKeyFromBase58(p.splTokenInfoMap[account(i).String()].Mint)
+		if err != nil {
+			return solana.PublicKey{}
+		}
+		return mint
+	}
+
+	switch {
+	case bytes.Equal(discriminator, ORCA_SWAP_DISCRIMINATOR[:]):
+		// accounts: token_program, token_authority, whirlpool, token_owner_account_a, token_vault_a, token_owner_account_b, token_vault_b, ...
+		if len(instruction.Accounts) < 7 {
+			return nil, fmt.Errorf("swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		var args OrcaSwapArgs
+		if err := decoder.Decode(&args); err != nil {
+			return nil, fmt.Errorf("error unmarshaling swap args: %s", err)
+		}
+		return &OrcaSwapInstruction{
+			Name:                   "swap",
+			Whirlpools:             []solana.PublicKey{account(2)},
+			AToB:                   []bool{args.AToB},
+			Amount:                 args.Amount,
+			OtherAmountThreshold:   args.OtherAmountThreshold,
+			AmountSpecifiedIsInput: args.AmountSpecifiedIsInput,
+			mints:                  [][2]solana.PublicKey{{vaultMint(4), vaultMint(6)}},
+		}, nil
+
+	case bytes.Equal(discriminator, ORCA_SWAP_V2_DISCRIMINATOR[:]):
+		// accounts: token_program_a, token_program_b, memo_program, token_authority, whirlpool, token_mint_a, token_mint_b, ...
+		if len(instruction.Accounts) < 7 {
+			return nil, fmt.Errorf("swapV2 instruction has %d accounts", len(instruction.Accounts))
+		}
+		var args OrcaSwapArgs
+		if err := decoder.Decode(&args); err != nil {
+			return nil, fmt.Errorf("error unmarshaling swapV2 args: %s", err)
+		}
+		return &OrcaSwapInstruction{
+			Name:                   "swapV2",
+			Whirlpools:             []solana.PublicKey{account(4)},
+			AToB:                   []bool{args.AToB},
+			Amount:                 args.Amount,
+			OtherAmountThreshold:   args.OtherAmountThreshold,
+			AmountSpecifiedIsInput: args.AmountSpecifiedIsInput,
+			mints:                  [][2]solana.PublicKey{{account(5), account(6)}},
+		}, nil
+
+	case bytes.Equal(discriminator, ORCA_TWO_HOP_SWAP_DISCRIMINATOR[:]):
+		// accounts: token_program, token_authority, whirlpool_one, whirlpool_two, token_owner_account_one_a, token_vault_one_a,
+		// token_owner_account_one_b, token_vault_one_b, token_owner_account_two_a, token_vault_two_a, token_owner_account_two_b, token_vault_two_b, ...
+		if len(instruction.Accounts) < 12 {
+			return nil, fmt.Errorf("twoHopSwap instruction has %d accounts", len(instruction.Accounts))
+		}
+		var args OrcaTwoHopSwapArgs
+		if err := decoder.Decode(&args); err != nil {
+			return nil, fmt.Errorf("error unmarshaling twoHopSwap args: %s", err)
+		}
+		return &OrcaSwapInstruction{
+			Name:                   "twoHopSwap",
+			Whirlpools:             []solana.PublicKey{account(2), account(3)},
+			AToB:                   []bool{args.AToBOne, args.AToBTwo},
+			Amount:                 args.Amount,
+			OtherAmountThreshold:   args.OtherAmountThreshold,
+			AmountSpecifiedIsInput: args.AmountSpecifiedIsInput,
+			mints: [][2]solana.PublicKey{
+				{vaultMint(5), vaultMint(7)},
+				{vaultMint(9), vaultMint(11)},
+			},
+		}, nil
+
+	case bytes.Equal(discriminator, ORCA_TWO_HOP_SWAP_V2_DISCRIMINATOR[:]):
+		// accounts: whirlpool_one, whirlpool_two, token_mint_input, token_mint_intermediate, token_mint_output, ...
+		if len(instruction.Accounts) < 5 {
+			return nil, fmt.Errorf("twoHopSwapV2 instruction has %d accounts", len(instruction.Accounts))
+		}
+		var args OrcaTwoHopSwapArgs
+		if err := decoder.Decode(&args); err != nil {
+			return nil, fmt.Errorf("error unmarshaling twoHopSwapV2 args: %s", err)
+		}
+		input, intermediate, output := account(2), account(3), account(4)
+		hopOne := [2]solana.PublicKey{input, intermediate}
+		if !args.AToBOne {
+			hopOne = [2]solana.PublicKey{intermediate, input}
+		}
+		hopTwo := [2]solana.PublicKey{intermediate, output}
+		if !args.AToBTwo {
+			hopTwo = [2]solana.PublicKey{output, intermediate}
+		}
+		return &OrcaSwapInstruction{
+			Name:                   "twoHopSwapV2",
+			Whirlpools:             []solana.PublicKey{account(0), account(1)},
+			AToB:                   []bool{args.AToBOne, args.AToBTwo},
+			Amount:                 args.Amount,
+			OtherAmountThreshold:   args.OtherAmountThreshold,
+			AmountSpecifiedIsInput: args.AmountSpecifiedIsInput,
+			mints:                  [][2]solana.PublicKey{hopOne, hopTwo},
+		}, nil
+	}
+
+	return nil, nil
+}
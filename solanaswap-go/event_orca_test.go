@@ -0,0 +1,105 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+func orcaSwapData(t *testing.T, discriminator [8]byte, args interface{}) []byte {
+	t.Helper()
+	return append(discriminator[:], borsh(t, args)...)
+}
+
+func TestOrcaTradedEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "whirlpool", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	// SOL (token A) for USDC
+	b.instruction(ORCA_PROGRAM_ID, orcaSwapData(t, ORCA_SWAP_DISCRIMINATOR, OrcaSwapArgs{
+		Amount:                 2_000_000_000,
+		OtherAmountThreshold:   299_000_000,
+		AmountSpecifiedIsInput: true,
+		AToB:                   true,
+	}), solana.TokenProgramID, testTrader, pool.address, pool.userA, pool.vaultA, pool.userB, pool.vaultB).
+		emit(anchorLogData(t, OrcaTradedEventDiscriminator, OrcaTradedEvent{
+			Whirlpool:     pool.address,
+			AToB:          true,
+			PreSqrtPrice:  ag_binary.Uint128{Lo: 1 << 62},
+			PostSqrtPrice: ag_binary.Uint128{Lo: 1 << 61},
+			InputAmount:   2_000_000_000,
+			OutputAmount:  300_000_000,
+			LpFee:         600_000,
+			ProtocolFee:   78_000,
+		}))
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 2_000_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 300_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	event := singleSwap[*OrcaTradedEventData](t, swapDatas)
+	if !event.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !event.OutputMint.Equals(testUSDC) {
+		t.Errorf("traded %s for %s, want SOL for USDC", event.InputMint, event.OutputMint)
+	}
+	if event.LpFee != 600_000 || event.ProtocolFee != 78_000 {
+		t.Errorf("fees %d/%d, want 600000/78000", event.LpFee, event.ProtocolFee)
+	}
+	assertSwap(t, swapInfo, NATIVE_SOL_MINT_PROGRAM_ID, 2_000_000_000, testUSDC, 300_000_000)
+}
+
+func TestOrcaTwoHopTradedEvents(t *testing.T) {
+	b := newTestTx(testTrader)
+	one := newTestPool(b, "whirlpool-one", testTrader, testMint, 6, NATIVE_SOL_MINT_PROGRAM_ID, 9)
+	two := newTestPool(b, "whirlpool-two", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	// token -> SOL on the first whirlpool (A to B), SOL -> USDC on the second (A to B)
+	b.instruction(ORCA_PROGRAM_ID, orcaSwapData(t, ORCA_TWO_HOP_SWAP_DISCRIMINATOR, OrcaTwoHopSwapArgs{
+		Amount:                 10_000_000,
+		AmountSpecifiedIsInput: true,
+		AToBOne:                true,
+		AToBTwo:                true,
+	}), solana.TokenProgramID, testTrader, one.address, two.address,
+		one.userA, one.vaultA, one.userB, one.vaultB, two.userA, two.vaultA, two.userB, two.vaultB).
+		emit(anchorLogData(t, OrcaTradedEventDiscriminator, OrcaTradedEvent{
+			Whirlpool: one.address, AToB: true, InputAmount: 10_000_000, OutputAmount: 50_000_000,
+		})).
+		emit(anchorLogData(t, OrcaTradedEventDiscriminator, OrcaTradedEvent{
+			Whirlpool: two.address, AToB: true, InputAmount: 50_000_000, OutputAmount: 7_500_000,
+		}))
+
+	_, swapDatas, swapInfo := b.parse(t)
+	if len(swapDatas) != 2 {
+		t.Fatalf("got %d swap data, want one Traded event per hop", len(swapDatas))
+	}
+	second := swapDatas[1].Data.(*OrcaTradedEventData)
+	if !second.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !second.OutputMint.Equals(testUSDC) {
+		t.Errorf("second hop traded %s for %s, want SOL for USDC", second.InputMint, second.OutputMint)
+	}
+	assertSwap(t, swapInfo, testMint, 10_000_000, testUSDC, 7_500_000)
+}
+
+// swaps from before the Traded event are reported as the decoded instruction and its transfers
+func TestOrcaSwapInstructionWithoutEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "whirlpool", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	// USDC (token B) for SOL, exact output
+	b.instruction(ORCA_PROGRAM_ID, orcaSwapData(t, ORCA_SWAP_V2_DISCRIMINATOR, OrcaSwapArgs{
+		Amount:               1_000_000_000,
+		OtherAmountThreshold: 151_000_000,
+		AToB:                 false,
+	}), solana.TokenProgramID, solana.TokenProgramID, testKey("memo"), testTrader, pool.address, pool.mintA, pool.mintB,
+		pool.userA, pool.vaultA, pool.userB, pool.vaultB)
+	b.transfer(0, 2, pool.userB, pool.vaultB, testTrader, 150_500_000)
+	b.transfer(0, 2, pool.vaultA, pool.userA, pool.address, 1_000_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	instruction := singleSwap[*OrcaSwapInstruction](t, swapDatas)
+	if instruction.Name != "swapV2" || instruction.AToB[0] || instruction.AmountSpecifiedIsInput {
+		t.Errorf("decoded %s a to b %v exact in %v, want an exact-out swapV2 from b to a", instruction.Name, instruction.AToB, instruction.AmountSpecifiedIsInput)
+	}
+	if !instruction.Whirlpools[0].Equals(pool.address) || instruction.OtherAmountThreshold != 151_000_000 {
+		t.Errorf("whirlpool %s threshold %d", instruction.Whirlpools[0], instruction.OtherAmountThreshold)
+	}
+	assertSwap(t, swapInfo, testUSDC, 150_500_000, NATIVE_SOL_MINT_PROGRAM_ID, 1_000_000_000)
+}
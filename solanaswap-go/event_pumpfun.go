@@ -35,18 +35,14 @@ type PumpfunCreateEvent struct {
 
 func (p *Parser) processPumpfunSwaps(instructionIndex int) []SwapData {
 	var swaps []SwapData
-	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
-		if innerInstructionSet.Index == uint16(instructionIndex) {
-			for _, innerInstruction := range innerInstructionSet.Instructions {
-				if p.isPumpFunTradeEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction)) {
-					eventData, err := p.parsePumpfunTradeEventInstruction(p.convertRPCToSolanaInstruction(innerInstruction))
-					if err != nil {
-						p.Log.Errorf("error processing Pumpfun trade event: %s", err)
-					}
-					if eventData != nil {
-						swaps = append(swaps, SwapData{Type: PUMP_FUN, Data: eventData})
-					}
-				}
+	for _, innerInstruction := range p.getInnerInstructions(instructionIndex) {
+		if p.isPumpFunTradeEventInstruction(innerInstruction) {
+			eventData, err := p.parsePumpfunTradeEventInstruction(innerInstruction)
+			if err != nil {
+				p.Log.Errorf("error processing Pumpfun trade event: %s", err)
+			}
+			if eventData != nil {
+				swaps = append(swaps, SwapData{Type: PUMP_FUN, Data: eventData})
 			}
 		}
 	}
@@ -54,26 +50,7 @@ func (p *Parser) processPumpfunSwaps(instructionIndex int) []SwapData {
 }
 
 func (p *Parser) processPumpfunAMMSwaps(instructionIndex int) []SwapData {
-	var swaps []SwapData
-	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
-		if innerInstructionSet.Index == uint16(instructionIndex) {
-			for _, innerInstruction := range innerInstructionSet.Instructions {
-				switch {
-				case p.isTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: PUMP_FUN, Data: transfer})
-					}
-				case p.isTransfer(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransfer(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: PUMP_FUN, Data: transfer})
-					}
-				}
-			}
-		}
-	}
-	return swaps
+	return p.processVenueTransfers(instructionIndex, PUMP_FUN, PUMPFUN_AMM_PROGRAM_ID)
 }
 
 func (p *Parser) parsePumpfunTradeEventInstruction(instruction solana.CompiledInstruction) (*PumpfunTradeEvent, error) {
@@ -0,0 +1,204 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"math/big"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+var RaydiumSwapEventDiscriminator = [8]byte{64, 198, 205, 232, 38, 8, 113, 226}
+
+type RaydiumClmmSwapEvent struct {
+	PoolState     solana.PublicKey
+	Sender        solana.PublicKey
+	TokenAccount0 solana.PublicKey
+	TokenAccount1 solana.PublicKey
+	Amount0       uint64
+	TransferFee0  uint64
+	Amount1       uint64
+	TransferFee1  uint64
+	ZeroForOne    bool
+	SqrtPriceX64  ag_binary.Uint128
+	Liquidity     ag_binary.Uint128
+	Tick          int32
+}
+
+type RaydiumClmmSwapEventData struct {
+	RaydiumClmmSwapEvent
+	Mint0         solana.PublicKey
+	Mint0Decimals uint8
+	Mint1         solana.PublicKey
+	Mint1Decimals uint8
+}
+
+// Price returns the post-trade pool price as token1 per token0, adjusted for decimals
+func (e *RaydiumClmmSwapEventData) Price() *big.Float {
+	sqrtPrice := new(big.Float).SetInt(e.SqrtPriceX64.BigInt())
+	sqrtPrice.Quo(sqrtPrice, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 64)))
+
+	price := new(big.Float).Mul(sqrtPrice, sqrtPrice)
+	decimalsDiff := int64(e.Mint0Decimals) - int64(e.Mint1Decimals)
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(decimalsDiff)), nil))
+	if decimalsDiff >= 0 {
+		return price.Mul(price, scale)
+	}
+	return price.Quo(price, scale)
+}
+
+// processRaydClmmSwaps decodes Raydium CLMM swap event logs, falling back to transfers for transactions without them
+// or whose events name token accounts missing from the token balances
+func (p *Parser) processRaydClmmSwaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, logData := range p.getProgramDataLogs(instructionIndex, RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID) {
+		if len(logData) < 8 || !bytes.Equal(logData[:8], RaydiumSwapEventDiscriminator[:]) {
+			continue
+		}
+		var event RaydiumClmmSwapEvent
+		if err := ag_binary.NewBorshDecoder(logData[8:]).Decode(&event); err != nil {
+			p.Log.Errorf("error processing Raydium CLMM swap event: %s", err)
+			continue
+		}
+
+		eventData := &RaydiumClmmSwapEventData{RaydiumClmmSwapEvent: event}
+		eventData.Mint0, eventData.Mint0Decimals = p.tokenAccountMint(event.TokenAccount0)
+		eventData.Mint1, eventData.Mint1Decimals = p.tokenAccountMint(event.TokenAccount1)
+		if eventData.Mint0.IsZero() || eventData.Mint1.IsZero() {
+			p.Log.Warnf("no token balances found for Raydium CLMM swap accounts %s and %s", event.TokenAccount0, event.TokenAccount1)
+			continue
+		}
+
+		swaps = append(swaps, SwapData{Type: RAYDIUM, Data: eventData})
+	}
+	if len(swaps) == 0 {
+		return p.processVenueTransfers(instructionIndex, RAYDIUM, RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID)
+	}
+	return swaps
+}
+
+// RaydiumCpmmFeeRateDenominator is the denominator of all CPMM AmmConfig fee rates
+const RaydiumCpmmFeeRateDenominator = 1_000_000
+
+// RaydiumCpmmFeeRate holds the rates of a CPMM AmmConfig account
+type RaydiumCpmmFeeRate struct {
+	TradeFeeRate    uint64
+	ProtocolFeeRate uint64
+	FundFeeRate     uint64
+}
+
+// raydiumCpmmFeeRates are the rates of the public CPMM AmmConfig accounts, the 0.25%, 1%, 2% and 4% fee tiers
+var raydiumCpmmFeeRates = map[solana.PublicKey]RaydiumCpmmFeeRate{
+	solana.MustPublicKeyFromBase58("D4FPEruKEHrG5TenZ2mpDGEfu1iUvTiqBxvpU8HLBvC2"): {TradeFeeRate: 2500, ProtocolFeeRate: 120000, FundFeeRate: 40000},
+	solana.MustPublicKeyFromBase58("G95xxie3XbkCqtE39GgQ9Ggc7xBC8Uceve7HFDEFApkc"): {TradeFeeRate: 10000, ProtocolFeeRate: 120000, FundFeeRate: 40000},
+	solana.MustPublicKeyFromBase58("2fGXL8uhqxJ4tpgtosHZXT4zcQap6j62z3bMDxdkMvy5"): {TradeFeeRate: 20000, ProtocolFeeRate: 120000, FundFeeRate: 40000},
+	solana.MustPublicKeyFromBase58("C7Cx2pMLtjybS3mDKSfsBj4zQ3PRZGkKt7RCYTTbCSx2"): {TradeFeeRate: 40000, ProtocolFeeRate: 120000, FundFeeRate: 40000},
+}
+
+// defaultRaydiumCpmmFeeRates returns a copy of the rates of the public CPMM AmmConfig accounts
+func defaultRaydiumCpmmFeeRates() map[solana.PublicKey]RaydiumCpmmFeeRate {
+	rates := make(map[solana.PublicKey]RaydiumCpmmFeeRate, len(raydiumCpmmFeeRates))
+	for config, rate := range raydiumCpmmFeeRates {
+		rates[config] = rate
+	}
+	return rates
+}
+
+type RaydiumCpmmSwapEvent struct {
+	PoolId            solana.PublicKey
+	InputVaultBefore  uint64
+	OutputVaultBefore uint64
+	InputAmount       uint64
+	OutputAmount      uint64
+	InputTransferFee  uint64
+	OutputTransferFee uint64
+	BaseInput         bool
+}
+
+// RaydiumCpmmSwapEventExtension holds the fields appended to SwapEvent by newer CPMM program versions
+type RaydiumCpmmSwapEventExtension struct {
+	InputMint         solana.PublicKey
+	OutputMint        solana.PublicKey
+	TradeFee          uint64
+	CreatorFee        uint64
+	CreatorFeeOnInput bool
+}
+
+type RaydiumCpmmSwapEventData struct {
+	RaydiumCpmmSwapEvent
+	AmmConfig          solana.PublicKey
+	InputMint          solana.PublicKey
+	InputMintDecimals  uint8
+	OutputMint         solana.PublicKey
+	OutputMintDecimals uint8
+
+	TradeFee    uint64
+	ProtocolFee uint64
+	FundFee     uint64
+	CreatorFee  uint64
+}
+
+// processRaydCpmmSwaps decodes Raydium CPMM swap event logs, falling back to transfers for transactions without them
+func (p *Parser) processRaydCpmmSwaps(instructionIndex int) []SwapData {
+	var swaps []SwapData
+	for _, logData := range p.getProgramDataLogs(instructionIndex, RAYDIUM_CPMM_PROGRAM_ID) {
+		if len(logData) < 8 || !bytes.Equal(logData[:8], RaydiumSwapEventDiscriminator[:]) {
+			continue
+		}
+		decoder := ag_binary.NewBorshDecoder(logData[8:])
+
+		var event RaydiumCpmmSwapEvent
+		if err := decoder.Decode(&event); err != nil {
+			p.Log.Errorf("error processing Raydium CPMM swap event: %s", err)
+			continue
+		}
+		eventData := &RaydiumCpmmSwapEventData{RaydiumCpmmSwapEvent: event}
+
+		// swap accounts: payer, authority, amm_config, pool_state, input_token_account, output_token_account,
+		// input_vault, output_vault, input_token_program, output_token_program, input_token_mint, output_token_mint, ...
+		swapInstruction, found := p.findInstruction(instructionIndex, func(inst solana.CompiledInstruction) bool {
+			return p.allAccountKeys[inst.ProgramIDIndex].Equals(RAYDIUM_CPMM_PROGRAM_ID) &&
+				len(inst.Accounts) >= 12 &&
+				p.allAccountKeys[inst.Accounts[3]].Equals(event.PoolId)
+		})
+		if found {
+			eventData.AmmConfig = p.allAccountKeys[swapInstruction.Accounts[2]]
+			eventData.InputMint = p.allAccountKeys[swapInstruction.Accounts[10]]
+			eventData.OutputMint = p.allAccountKeys[swapInstruction.Accounts[11]]
+		}
+
+		var extension RaydiumCpmmSwapEventExtension
+		if decoder.Remaining() > 0 {
+			if err := decoder.Decode(&extension); err != nil {
+				p.Log.Warnf("error decoding Raydium CPMM swap event extension: %s", err)
+			} else {
+				eventData.InputMint = extension.InputMint
+				eventData.OutputMint = extension.OutputMint
+				eventData.TradeFee = extension.TradeFee
+				eventData.CreatorFee = extension.CreatorFee
+			}
+		}
+
+		if eventData.InputMint.IsZero() || eventData.OutputMint.IsZero() {
+			p.Log.Warnf("no Raydium CPMM swap instruction found for pool %s", event.PoolId)
+			continue
+		}
+		eventData.InputMintDecimals = p.splDecimalsMap[eventData.InputMint.String()]
+		eventData.OutputMintDecimals = p.splDecimalsMap[eventData.OutputMint.String()]
+
+		if rate, ok := p.RaydiumCpmmFeeRates[eventData.AmmConfig]; ok {
+			// the event's input amount is what reached the vault, the input transfer fee is already taken out
+			if eventData.TradeFee == 0 {
+				eventData.TradeFee = mulDivCeil(event.InputAmount, rate.TradeFeeRate, RaydiumCpmmFeeRateDenominator)
+			}
+			eventData.ProtocolFee = mulDivFloor(eventData.TradeFee, rate.ProtocolFeeRate, RaydiumCpmmFeeRateDenominator)
+			eventData.FundFee = mulDivFloor(eventData.TradeFee, rate.FundFeeRate, RaydiumCpmmFeeRateDenominator)
+		}
+
+		swaps = append(swaps, SwapData{Type: RAYDIUM, Data: eventData})
+	}
+	if len(swaps) == 0 {
+		return p.processVenueTransfers(instructionIndex, RAYDIUM, RAYDIUM_CPMM_PROGRAM_ID)
+	}
+	return swaps
+}
@@ -0,0 +1,185 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestRaydiumClmmSwapEvent(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "clmm", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	// token 0 (SOL) for token 1 (USDC)
+	b.instruction(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID, anchorSwapData,
+		testTrader, testKey("clmm/config"), pool.address, pool.userA, pool.userB, pool.vaultA, pool.vaultB).
+		emit(anchorLogData(t, RaydiumSwapEventDiscriminator, RaydiumClmmSwapEvent{
+			PoolState:     pool.address,
+			Sender:        testTrader,
+			TokenAccount0: pool.userA,
+			TokenAccount1: pool.userB,
+			Amount0:       1_000_000_000,
+			Amount1:       4_000_000,
+			ZeroForOne:    true,
+			SqrtPriceX64:  ag_binary.Uint128{Hi: 2},
+			Liquidity:     ag_binary.Uint128{Lo: 5_000_000_000},
+			Tick:          -69082,
+		}))
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 1_000_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 4_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	event := singleSwap[*RaydiumClmmSwapEventData](t, swapDatas)
+	if !event.Mint0.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || event.Mint0Decimals != 9 || !event.Mint1.Equals(testUSDC) || event.Mint1Decimals != 6 {
+		t.Errorf("mints %s (%d) and %s (%d)", event.Mint0, event.Mint0Decimals, event.Mint1, event.Mint1Decimals)
+	}
+	if event.Tick != -69082 || event.Liquidity.Lo != 5_000_000_000 {
+		t.Errorf("tick %d liquidity %d", event.Tick, event.Liquidity.Lo)
+	}
+	// a sqrt price of 2 is 4 raw token 1 per token 0, 4000 once the 3 extra decimals of token 0 are accounted for
+	if price, _ := event.Price().Float64(); price != 4000 {
+		t.Errorf("price %f, want 4000", price)
+	}
+	assertSwap(t, swapInfo, NATIVE_SOL_MINT_PROGRAM_ID, 1_000_000_000, testUSDC, 4_000_000)
+}
+
+// an event naming a token account without token balances is skipped for the transfers
+func TestRaydiumClmmUnknownTokenAccount(t *testing.T) {
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "clmm", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	b.instruction(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID, anchorSwapData,
+		testTrader, testKey("clmm/config"), pool.address, pool.userA, pool.userB, pool.vaultA, pool.vaultB).
+		emit(anchorLogData(t, RaydiumSwapEventDiscriminator, RaydiumClmmSwapEvent{
+			PoolState:     pool.address,
+			TokenAccount0: pool.userA,
+			TokenAccount1: testKey("unknown"),
+			Amount0:       1_000_000_000,
+			Amount1:       4_000_000,
+			ZeroForOne:    true,
+		}))
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 1_000_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 4_000_000)
+
+	_, swapDatas, swapInfo := b.parse(t)
+	for _, swapData := range swapDatas {
+		if _, ok := swapData.Data.(*RaydiumClmmSwapEventData); ok {
+			t.Fatal("event with an unknown token account was reported")
+		}
+	}
+	if len(swapDatas) != 2 {
+		t.Fatalf("got %d swap data, want the 2 transfers", len(swapDatas))
+	}
+	assertSwap(t, swapInfo, NATIVE_SOL_MINT_PROGRAM_ID, 1_000_000_000, testUSDC, 4_000_000)
+}
+
+// cpmmSwapTx is a CPMM swap of 1 SOL for 150 USDC against the given AmmConfig, the trader sends the input transfer
+// fee on top of the 1 SOL reaching the vault. The event is followed by extension fields when any are given.
+func cpmmSwapTx(t *testing.T, ammConfig solana.PublicKey, inputTransferFee uint64, extension ...RaydiumCpmmSwapEventExtension) *testTx {
+	t.Helper()
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "cpmm", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+
+	event := []interface{}{RaydiumCpmmSwapEvent{
+		PoolId:            pool.address,
+		InputVaultBefore:  500_000_000_000,
+		OutputVaultBefore: 75_000_000_000,
+		InputAmount:       1_000_000_000,
+		OutputAmount:      150_000_000,
+		InputTransferFee:  inputTransferFee,
+		BaseInput:         true,
+	}}
+	for _, fields := range extension {
+		event = append(event, fields)
+	}
+	b.instruction(RAYDIUM_CPMM_PROGRAM_ID, []byte{143, 190, 90, 218, 196, 30, 51, 222},
+		testTrader, testKey("cpmm/authority"), ammConfig, pool.address, pool.userA, pool.userB, pool.vaultA, pool.vaultB,
+		solana.TokenProgramID, solana.TokenProgramID, pool.mintA, pool.mintB).
+		emit(append(RaydiumSwapEventDiscriminator[:], borsh(t, event...)...))
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 1_000_000_000+inputTransferFee)
+	b.transfer(0, 2, pool.vaultB, pool.userB, testKey("cpmm/authority"), 150_000_000)
+	return b
+}
+
+func TestRaydiumCpmmSwapEventFees(t *testing.T) {
+	custom := testKey("cpmm/custom-config")
+	tests := []struct {
+		name   string
+		config solana.PublicKey
+		// transferFee is the input transfer fee, the event's input amount already excludes it
+		transferFee uint64
+		extension   []RaydiumCpmmSwapEventExtension
+		override    *RaydiumCpmmFeeRate
+		want        [4]uint64 // trade, protocol, fund and creator fees
+	}{
+		{
+			name:   "public 0.25% config",
+			config: solana.MustPublicKeyFromBase58("D4FPEruKEHrG5TenZ2mpDGEfu1iUvTiqBxvpU8HLBvC2"),
+			want:   [4]uint64{2_500_000, 300_000, 100_000, 0},
+		},
+		{
+			name:        "input transfer fee",
+			config:      solana.MustPublicKeyFromBase58("D4FPEruKEHrG5TenZ2mpDGEfu1iUvTiqBxvpU8HLBvC2"),
+			transferFee: 10_000_000,
+			want:        [4]uint64{2_500_000, 300_000, 100_000, 0},
+		},
+		{
+			name:   "unknown config",
+			config: custom,
+			want:   [4]uint64{0, 0, 0, 0},
+		},
+		{
+			name:     "parser override",
+			config:   custom,
+			override: &RaydiumCpmmFeeRate{TradeFeeRate: 3000, ProtocolFeeRate: 100_000, FundFeeRate: 50_000},
+			want:     [4]uint64{3_000_000, 300_000, 150_000, 0},
+		},
+		{
+			name:   "trade fee from the event extension",
+			config: solana.MustPublicKeyFromBase58("G95xxie3XbkCqtE39GgQ9Ggc7xBC8Uceve7HFDEFApkc"),
+			extension: []RaydiumCpmmSwapEventExtension{{
+				InputMint:  NATIVE_SOL_MINT_PROGRAM_ID,
+				OutputMint: testUSDC,
+				TradeFee:   9_000_000,
+				CreatorFee: 1_000_000,
+			}},
+			want: [4]uint64{9_000_000, 1_080_000, 360_000, 1_000_000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := cpmmSwapTx(t, tt.config, tt.transferFee, tt.extension...).parser(t)
+			if tt.override != nil {
+				parser.RaydiumCpmmFeeRates[tt.config] = *tt.override
+			}
+			swapDatas, err := parser.ParseTransaction()
+			if err != nil {
+				t.Fatal(err)
+			}
+			event := singleSwap[*RaydiumCpmmSwapEventData](t, swapDatas)
+			if !event.AmmConfig.Equals(tt.config) {
+				t.Errorf("amm config %s, want %s", event.AmmConfig, tt.config)
+			}
+			if !event.InputMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !event.OutputMint.Equals(testUSDC) || event.InputMintDecimals != 9 || event.OutputMintDecimals != 6 {
+				t.Errorf("swapped %s (%d) for %s (%d)", event.InputMint, event.InputMintDecimals, event.OutputMint, event.OutputMintDecimals)
+			}
+			got := [4]uint64{event.TradeFee, event.ProtocolFee, event.FundFee, event.CreatorFee}
+			if got != tt.want {
+				t.Errorf("fees %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// overriding the rates of one parser leaves the defaults of the others alone
+func TestRaydiumCpmmFeeRatesPerParser(t *testing.T) {
+	config := solana.MustPublicKeyFromBase58("D4FPEruKEHrG5TenZ2mpDGEfu1iUvTiqBxvpU8HLBvC2")
+	first := cpmmSwapTx(t, config, 0).parser(t)
+	first.RaydiumCpmmFeeRates[config] = RaydiumCpmmFeeRate{}
+
+	second := cpmmSwapTx(t, config, 0).parser(t)
+	if second.RaydiumCpmmFeeRates[config].TradeFeeRate != 2500 {
+		t.Errorf("trade fee rate %d, want the default 2500", second.RaydiumCpmmFeeRates[config].TradeFeeRate)
+	}
+}
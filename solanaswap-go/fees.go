@@ -0,0 +1,63 @@
+package solanaswapgo
+
+import (
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// FeeKind tells what a Fee was paid for
+type FeeKind string
+
+const (
+	// FeeKindPlatform is a fee taken by the integrator routing the swap, e.g. a Jupiter platform fee
+	FeeKindPlatform FeeKind = "platform"
+	// FeeKindBot is a fee skimmed by the trading bot or router that executed the swap
+	FeeKindBot FeeKind = "bot"
+)
+
+// Fee is an amount paid out of a swap to a party other than the pools, Recipient is the owner of the
+// account credited with the fee when the transaction records it, otherwise the credited account itself
+type Fee struct {
+	Kind      FeeKind
+	Mint      solana.PublicKey
+	Amount    uint64
+	Recipient solana.PublicKey
+}
+
+// tokenAccountOwner returns the owner of a token account recorded in the transaction token balances
+func (p *Parser) tokenAccountOwner(account solana.PublicKey) (solana.PublicKey, bool) {
+	for _, balances := range [][]rpc.TokenBalance{p.txMeta.PostTokenBalances, p.txMeta.PreTokenBalances} {
+		for _, balance := range balances {
+			if int(balance.AccountIndex) >= len(p.allAccountKeys) || balance.Owner == nil {
+				continue
+			}
+			if p.allAccountKeys[balance.AccountIndex].Equals(account) {
+				return *balance.Owner, true
+			}
+		}
+	}
+	return solana.PublicKey{}, false
+}
+
+// isOwnedBy reports whether an account is the owner itself or a token account it owns
+func (p *Parser) isOwnedBy(account, owner solana.PublicKey) bool {
+	if account.Equals(owner) {
+		return true
+	}
+	accountOwner, ok := p.tokenAccountOwner(account)
+	return ok && accountOwner.Equals(owner)
+}
+
+// jupiterPlatformFee converts a Jupiter FeeEvent, credited to a referral token account, into a platform fee
+func (p *Parser) jupiterPlatformFee(event *JupiterFeeEvent) Fee {
+	recipient := event.Account
+	if owner, ok := p.tokenAccountOwner(event.Account); ok {
+		recipient = owner
+	}
+	return Fee{
+		Kind:      FeeKindPlatform,
+		Mint:      event.Mint,
+		Amount:    event.Amount,
+		Recipient: recipient,
+	}
+}
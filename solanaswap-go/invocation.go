@@ -0,0 +1,100 @@
+package solanaswapgo
+
+import "github.com/gagliardetto/solana-go"
+
+// InvocationKey identifies a venue invocation by its outer instruction and its position among the inner instructions
+type InvocationKey struct {
+	OuterIndex int
+	InnerIndex int
+}
+
+// getInvocationProtocol returns the decoder a venue program is handled by, or "" when it has none. Programs sharing
+// a decoder share a protocol.
+func getInvocationProtocol(progID solana.PublicKey) string {
+	switch {
+	case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+		return PROTOCOL_RAYDIUM_CLMM
+	case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+		return PROTOCOL_RAYDIUM_CPMM
+	case progID.Equals(RAYDIUM_V4_PROGRAM_ID) || progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
+		progID.Equals(RAYDIUM_LAUNCHLAB_PROGRAM_ID) || progID.Equals(RAYDIUM_AP51_PROGRAM_ID):
+		return PROTOCOL_RAYDIUM
+	case progID.Equals(ORCA_PROGRAM_ID):
+		return PROTOCOL_ORCA
+	case progID.Equals(METEORA_PROGRAM_ID):
+		return PROTOCOL_METEORA_DLMM
+	case progID.Equals(METEORA_POOLS_PROGRAM_ID) || progID.Equals(METEORA_DLMM_PROGRAM_ID):
+		return PROTOCOL_METEORA
+	case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID):
+		return PROTOCOL_METEORA_DAMM_V2
+	case progID.Equals(METEORA_DBC_PROGRAM_ID):
+		return PROTOCOL_METEORA_DBC
+	case progID.Equals(OPENBOOK_V2_PROGRAM_ID):
+		return PROTOCOL_OPENBOOK
+	case progID.Equals(OPENBOOK_V1_PROGRAM_ID) || progID.Equals(SERUM_V3_PROGRAM_ID):
+		return PROTOCOL_SERUM
+	case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
+		return PROTOCOL_PUMPSWAP
+	case progID.Equals(PUMP_FUN_PROGRAM_ID) || progID.Equals(PHOTON_PROGRAM_ID):
+		return PROTOCOL_PUMPFUN
+	case isStableSwapProgram(progID) || isPropAMMProgram(progID):
+		return progID.String()
+	}
+	return ""
+}
+
+// decodeVenue runs the decoder of a venue program over an outer instruction
+func (p *Parser) decodeVenue(instructionIndex int, progID solana.PublicKey) []SwapData {
+	switch getInvocationProtocol(progID) {
+	case PROTOCOL_RAYDIUM_CLMM:
+		return p.processRaydClmmSwaps(instructionIndex)
+	case PROTOCOL_RAYDIUM_CPMM:
+		return p.processRaydCpmmSwaps(instructionIndex)
+	case PROTOCOL_RAYDIUM:
+		return p.processRaydSwaps(instructionIndex)
+	case PROTOCOL_ORCA:
+		return p.processOrcaSwaps(instructionIndex)
+	case PROTOCOL_METEORA_DLMM:
+		return p.processMeteoraDlmmSwaps(instructionIndex)
+	case PROTOCOL_METEORA:
+		return p.processMeteoraSwaps(instructionIndex)
+	case PROTOCOL_METEORA_DAMM_V2:
+		return p.processMeteoraDammV2Swaps(instructionIndex)
+	case PROTOCOL_METEORA_DBC:
+		return p.processMeteoraDbcSwaps(instructionIndex)
+	case PROTOCOL_OPENBOOK:
+		return p.processOpenbookV2Trades(instructionIndex)
+	case PROTOCOL_SERUM:
+		return p.processOpenbookV1Trades(instructionIndex, solana.PublicKey{})
+	case PROTOCOL_PUMPSWAP:
+		return p.processPumpfunAMMSwaps(instructionIndex)
+	case PROTOCOL_PUMPFUN:
+		return p.processPumpfunSwaps(instructionIndex)
+	}
+	switch {
+	case isStableSwapProgram(progID):
+		return p.processStableSwaps(instructionIndex, progID)
+	case isPropAMMProgram(progID):
+		return p.processPropAMMSwaps(instructionIndex, progID)
+	}
+	return nil
+}
+
+// processInvocation decodes a single venue invocation, the decoders only see the instructions, transfers and
+// logs of the invocation's subtree while it is in scope
+func (p *Parser) processInvocation(node *InstructionNode) []SwapData {
+	p.scope = node
+	defer func() { p.scope = nil }()
+	return p.decodeVenue(node.OuterIndex, node.ProgramID)
+}
+
+// decodedByAncestor reports whether the node runs under an invocation of the same protocol, whose decoder already
+// covers the node's subtree (e.g. a Raydium route calling Raydium V4)
+func (p *Parser) decodedByAncestor(node *InstructionNode, protocol string) bool {
+	for parent := node.Parent; parent != nil && parent.InnerIndex >= 0; parent = parent.Parent {
+		if getInvocationProtocol(parent.ProgramID) == protocol {
+			return true
+		}
+	}
+	return false
+}
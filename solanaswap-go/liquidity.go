@@ -0,0 +1,297 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+const (
+	RAYDIUM_V4_DEPOSIT_INSTRUCTION  = 3
+	RAYDIUM_V4_WITHDRAW_INSTRUCTION = 4
+
+	TOKEN_MINT_TO_INSTRUCTION         = 7
+	TOKEN_BURN_INSTRUCTION            = 8
+	TOKEN_MINT_TO_CHECKED_INSTRUCTION = 14
+	TOKEN_BURN_CHECKED_INSTRUCTION    = 15
+)
+
+var (
+	DEPOSIT_DISCRIMINATOR                        = [8]byte{242, 35, 198, 137, 82, 225, 242, 182}
+	WITHDRAW_DISCRIMINATOR                       = [8]byte{183, 18, 70, 156, 148, 109, 161, 34}
+	INCREASE_LIQUIDITY_DISCRIMINATOR             = [8]byte{46, 156, 243, 118, 13, 205, 251, 178}
+	INCREASE_LIQUIDITY_V2_DISCRIMINATOR          = [8]byte{133, 29, 89, 223, 69, 238, 176, 10}
+	DECREASE_LIQUIDITY_DISCRIMINATOR             = [8]byte{160, 38, 208, 111, 104, 91, 44, 1}
+	DECREASE_LIQUIDITY_V2_DISCRIMINATOR          = [8]byte{58, 127, 188, 62, 79, 82, 196, 96}
+	OPEN_POSITION_V2_DISCRIMINATOR               = [8]byte{77, 184, 74, 214, 112, 86, 241, 199}
+	OPEN_POSITION_WITH_TOKEN22_NFT_DISCRIMINATOR = [8]byte{77, 255, 174, 82, 125, 29, 201, 46}
+
+	DLMM_ADD_LIQUIDITY_DISCRIMINATOR                      = [8]byte{181, 157, 89, 67, 143, 182, 52, 72}
+	DLMM_ADD_LIQUIDITY_BY_WEIGHT_DISCRIMINATOR            = [8]byte{28, 140, 238, 99, 231, 162, 21, 149}
+	DLMM_ADD_LIQUIDITY_BY_STRATEGY_DISCRIMINATOR          = [8]byte{7, 3, 150, 127, 148, 40, 61, 200}
+	DLMM_ADD_LIQUIDITY_BY_STRATEGY_ONE_SIDE_DISCRIMINATOR = [8]byte{41, 5, 238, 175, 100, 225, 6, 205}
+	DLMM_ADD_LIQUIDITY_ONE_SIDE_DISCRIMINATOR             = [8]byte{94, 155, 103, 151, 70, 95, 220, 165}
+	DLMM_ADD_LIQUIDITY_ONE_SIDE_PRECISE_DISCRIMINATOR     = [8]byte{161, 194, 103, 84, 171, 71, 250, 154}
+	DLMM_ADD_LIQUIDITY2_DISCRIMINATOR                     = [8]byte{228, 162, 78, 28, 70, 219, 116, 115}
+	DLMM_ADD_LIQUIDITY_BY_STRATEGY2_DISCRIMINATOR         = [8]byte{3, 221, 149, 218, 111, 141, 118, 213}
+	DLMM_ADD_LIQUIDITY_ONE_SIDE_PRECISE2_DISCRIMINATOR    = [8]byte{33, 51, 163, 201, 117, 98, 125, 231}
+	DLMM_REMOVE_LIQUIDITY_DISCRIMINATOR                   = [8]byte{80, 85, 209, 72, 24, 206, 177, 108}
+	DLMM_REMOVE_LIQUIDITY_BY_RANGE_DISCRIMINATOR          = [8]byte{26, 82, 102, 152, 240, 74, 105, 26}
+	DLMM_REMOVE_ALL_LIQUIDITY_DISCRIMINATOR               = [8]byte{10, 51, 61, 35, 112, 105, 24, 85}
+	DLMM_REMOVE_LIQUIDITY2_DISCRIMINATOR                  = [8]byte{230, 215, 82, 127, 241, 101, 227, 146}
+	DLMM_REMOVE_LIQUIDITY_BY_RANGE2_DISCRIMINATOR         = [8]byte{204, 2, 195, 145, 53, 145, 145, 205}
+)
+
+// LiquidityAction tells whether liquidity was added to or removed from a pool
+type LiquidityAction string
+
+const (
+	LiquidityAdd    LiquidityAction = "add"
+	LiquidityRemove LiquidityAction = "remove"
+)
+
+// liquidityInstruction describes a liquidity instruction of a venue, Pool and Position are account positions,
+// Position is -1 for venues whose liquidity is tracked with LP tokens
+type liquidityInstruction struct {
+	Name     string
+	Action   LiquidityAction
+	Pool     int
+	Position int
+}
+
+// liquidityInstructions maps the anchor liquidity instructions of the supported venues by discriminator
+var liquidityInstructions = map[solana.PublicKey]map[[8]byte]liquidityInstruction{
+	RAYDIUM_CPMM_PROGRAM_ID: {
+		DEPOSIT_DISCRIMINATOR:  {Name: "deposit", Action: LiquidityAdd, Pool: 2, Position: -1},
+		WITHDRAW_DISCRIMINATOR: {Name: "withdraw", Action: LiquidityRemove, Pool: 2, Position: -1},
+	},
+	RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID: {
+		OPEN_POSITION_V2_DISCRIMINATOR:               {Name: "openPositionV2", Action: LiquidityAdd, Pool: 5, Position: 9},
+		OPEN_POSITION_WITH_TOKEN22_NFT_DISCRIMINATOR: {Name: "openPositionWithToken22Nft", Action: LiquidityAdd, Pool: 4, Position: 8},
+		INCREASE_LIQUIDITY_DISCRIMINATOR:             {Name: "increaseLiquidity", Action: LiquidityAdd, Pool: 2, Position: 4},
+		INCREASE_LIQUIDITY_V2_DISCRIMINATOR:          {Name: "increaseLiquidityV2", Action: LiquidityAdd, Pool: 2, Position: 4},
+		DECREASE_LIQUIDITY_DISCRIMINATOR:             {Name: "decreaseLiquidity", Action: LiquidityRemove, Pool: 3, Position: 2},
+		DECREASE_LIQUIDITY_V2_DISCRIMINATOR:          {Name: "decreaseLiquidityV2", Action: LiquidityRemove, Pool: 3, Position: 2},
+	},
+	ORCA_PROGRAM_ID: {
+		INCREASE_LIQUIDITY_DISCRIMINATOR:    {Name: "increaseLiquidity", Action: LiquidityAdd, Pool: 0, Position: 3},
+		INCREASE_LIQUIDITY_V2_DISCRIMINATOR: {Name: "increaseLiquidityV2", Action: LiquidityAdd, Pool: 0, Position: 5},
+		DECREASE_LIQUIDITY_DISCRIMINATOR:    {Name: "decreaseLiquidity", Action: LiquidityRemove, Pool: 0, Position: 3},
+		DECREASE_LIQUIDITY_V2_DISCRIMINATOR: {Name: "decreaseLiquidityV2", Action: LiquidityRemove, Pool: 0, Position: 5},
+	},
+	METEORA_PROGRAM_ID: {
+		DLMM_ADD_LIQUIDITY_DISCRIMINATOR:                      {Name: "addLiquidity", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_BY_WEIGHT_DISCRIMINATOR:            {Name: "addLiquidityByWeight", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_BY_STRATEGY_DISCRIMINATOR:          {Name: "addLiquidityByStrategy", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_BY_STRATEGY_ONE_SIDE_DISCRIMINATOR: {Name: "addLiquidityByStrategyOneSide", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_ONE_SIDE_DISCRIMINATOR:             {Name: "addLiquidityOneSide", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_ONE_SIDE_PRECISE_DISCRIMINATOR:     {Name: "addLiquidityOneSidePrecise", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY2_DISCRIMINATOR:                     {Name: "addLiquidity2", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_BY_STRATEGY2_DISCRIMINATOR:         {Name: "addLiquidityByStrategy2", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_ADD_LIQUIDITY_ONE_SIDE_PRECISE2_DISCRIMINATOR:    {Name: "addLiquidityOneSidePrecise2", Action: LiquidityAdd, Pool: 1, Position: 0},
+		DLMM_REMOVE_LIQUIDITY_DISCRIMINATOR:                   {Name: "removeLiquidity", Action: LiquidityRemove, Pool: 1, Position: 0},
+		DLMM_REMOVE_LIQUIDITY_BY_RANGE_DISCRIMINATOR:          {Name: "removeLiquidityByRange", Action: LiquidityRemove, Pool: 1, Position: 0},
+		DLMM_REMOVE_ALL_LIQUIDITY_DISCRIMINATOR:               {Name: "removeAllLiquidity", Action: LiquidityRemove, Pool: 1, Position: 0},
+		DLMM_REMOVE_LIQUIDITY2_DISCRIMINATOR:                  {Name: "removeLiquidity2", Action: LiquidityRemove, Pool: 1, Position: 0},
+		DLMM_REMOVE_LIQUIDITY_BY_RANGE2_DISCRIMINATOR:         {Name: "removeLiquidityByRange2", Action: LiquidityRemove, Pool: 1, Position: 0},
+	},
+	PUMPFUN_AMM_PROGRAM_ID: {
+		DEPOSIT_DISCRIMINATOR:  {Name: "deposit", Action: LiquidityAdd, Pool: 0, Position: -1},
+		WITHDRAW_DISCRIMINATOR: {Name: "withdraw", Action: LiquidityRemove, Pool: 0, Position: -1},
+	},
+}
+
+// LiquidityEvent is a deposit into or a withdrawal from a pool. The amounts are the token transfers executed by
+// the instruction, in the order their mints first appear, and LPAmount the LP tokens minted to or burned by the
+// provider. Concentrated liquidity venues have no LP token, their liquidity is held by Position.
+type LiquidityEvent struct {
+	Signature   solana.Signature
+	Program     solana.PublicKey
+	Instruction string
+	Action      LiquidityAction
+	Pool        solana.PublicKey
+	Position    solana.PublicKey
+	Provider    solana.PublicKey
+
+	MintA     solana.PublicKey
+	AmountA   uint64
+	DecimalsA uint8
+	MintB     solana.PublicKey
+	AmountB   uint64
+	DecimalsB uint8
+	LPMint    solana.PublicKey
+	LPAmount  uint64
+}
+
+// getLiquidityInstruction returns the description of a liquidity instruction of a supported venue
+func (p *Parser) getLiquidityInstruction(instr solana.CompiledInstruction) (liquidityInstruction, bool) {
+	progID := p.allAccountKeys[instr.ProgramIDIndex]
+	decodedBytes, err := base58.Decode(instr.Data.String())
+	if err != nil || len(decodedBytes) == 0 {
+		return liquidityInstruction{}, false
+	}
+
+	if progID.Equals(RAYDIUM_V4_PROGRAM_ID) {
+		// accounts: token_program, amm, ...
+		switch decodedBytes[0] {
+		case RAYDIUM_V4_DEPOSIT_INSTRUCTION:
+			return liquidityInstruction{Name: "deposit", Action: LiquidityAdd, Pool: 1, Position: -1}, true
+		case RAYDIUM_V4_WITHDRAW_INSTRUCTION:
+			return liquidityInstruction{Name: "withdraw", Action: LiquidityRemove, Pool: 1, Position: -1}, true
+		}
+		return liquidityInstruction{}, false
+	}
+
+	instructions, ok := liquidityInstructions[progID]
+	if !ok || len(decodedBytes) < 8 {
+		return liquidityInstruction{}, false
+	}
+	var discriminator [8]byte
+	copy(discriminator[:], decodedBytes[:8])
+	liquidity, ok := instructions[discriminator]
+	return liquidity, ok
+}
+
+func (p *Parser) isLiquidityInstruction(instr solana.CompiledInstruction) bool {
+	_, ok := p.getLiquidityInstruction(instr)
+	return ok
+}
+
+// underNonSwapInstruction reports whether the node is a liquidity or pool creation instruction or was invoked by one
+func (p *Parser) underNonSwapInstruction(node *InstructionNode) bool {
+	for ; node != nil; node = node.Parent {
+		if p.isNonSwapInstruction(node.Instruction) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLiquidityEvents returns the liquidity added to and removed from the supported venues, top-level or inside a
+// router's CPI. Without stack heights only top-level liquidity instructions are recognised.
+func (p *Parser) GetLiquidityEvents() []LiquidityEvent {
+	var events []LiquidityEvent
+	for i, root := range p.GetCallTree() {
+		nodes := []*InstructionNode{root}
+		if p.hasStackHeights(i) {
+			nodes = append(nodes, root.descendants()...)
+		}
+		for _, node := range nodes {
+			liquidity, ok := p.getLiquidityInstruction(node.Instruction)
+			if !ok {
+				continue
+			}
+			if event := p.newLiquidityEvent(node, liquidity); event != nil {
+				events = append(events, *event)
+			}
+		}
+	}
+	return events
+}
+
+func (p *Parser) newLiquidityEvent(node *InstructionNode, liquidity liquidityInstruction) *LiquidityEvent {
+	account := func(i int) (solana.PublicKey, bool) {
+		if i < 0 || i >= len(node.Instruction.Accounts) || int(node.Instruction.Accounts[i]) >= len(p.allAccountKeys) {
+			return solana.PublicKey{}, false
+		}
+		return p.allAccountKeys[node.Instruction.Accounts[i]], true
+	}
+
+	pool, ok := account(liquidity.Pool)
+	if !ok {
+		p.Log.Warnf("%s instruction has %d accounts", liquidity.Name, len(node.Instruction.Accounts))
+		return nil
+	}
+	event := &LiquidityEvent{
+		Signature:   p.txInfo.Signatures[0],
+		Program:     node.ProgramID,
+		Instruction: liquidity.Name,
+		Action:      liquidity.Action,
+		Pool:        pool,
+	}
+	event.Position, _ = account(liquidity.Position)
+
+	var mints []TokenTransfer
+	addAmount := func(transfer TokenTransfer, userAccount string) {
+		if event.Provider.IsZero() {
+			if key, err := solana.PublicKeyFromBase58(userAccount); err == nil {
+				if owner, ok := p.tokenAccountOwner(key); ok {
+					event.Provider = owner
+				}
+			}
+		}
+		for i := range mints {
+			if mints[i].mint == transfer.mint {
+				mints[i].amount += transfer.amount
+				return
+			}
+		}
+		mints = append(mints, transfer)
+	}
+
+	for _, child := range node.descendants() {
+		instr := child.Instruction
+		switch {
+		case p.isTransfer(instr):
+			transfer := p.processTransfer(instr)
+			userAccount := transfer.Info.Source
+			if event.Action == LiquidityRemove {
+				userAccount = transfer.Info.Destination
+			}
+			addAmount(TokenTransfer{mint: transfer.Mint, amount: transfer.Info.Amount, decimals: transfer.Decimals}, userAccount)
+		case p.isTransferCheck(instr):
+			transfer := p.processTransferCheck(instr)
+			if transfer == nil {
+				continue
+			}
+			amount, err := strconv.ParseUint(transfer.Info.TokenAmount.Amount, 10, 64)
+			if err != nil {
+				continue
+			}
+			userAccount := transfer.Info.Source
+			if event.Action == LiquidityRemove {
+				userAccount = transfer.Info.Destination
+			}
+			addAmount(TokenTransfer{mint: transfer.Info.Mint, amount: amount, decimals: transfer.Info.TokenAmount.Decimals}, userAccount)
+		case liquidity.Position < 0 && p.isLPMintOrBurn(instr):
+			// mint_to: mint, destination, authority; burn: account, mint, authority
+			data := instr.Data
+			switch data[0] {
+			case TOKEN_MINT_TO_INSTRUCTION, TOKEN_MINT_TO_CHECKED_INSTRUCTION:
+				event.LPMint = p.allAccountKeys[instr.Accounts[0]]
+			default:
+				event.LPMint = p.allAccountKeys[instr.Accounts[1]]
+			}
+			event.LPAmount += binary.LittleEndian.Uint64(data[1:9])
+		}
+	}
+
+	if len(mints) > 0 {
+		event.MintA, _ = solana.PublicKeyFromBase58(mints[0].mint)
+		event.AmountA, event.DecimalsA = mints[0].amount, mints[0].decimals
+	}
+	if len(mints) > 1 {
+		event.MintB, _ = solana.PublicKeyFromBase58(mints[1].mint)
+		event.AmountB, event.DecimalsB = mints[1].amount, mints[1].decimals
+	}
+	return event
+}
+
+// isLPMintOrBurn reports whether the instruction mints or burns tokens, within a liquidity instruction these are
+// the pool's LP tokens
+func (p *Parser) isLPMintOrBurn(instr solana.CompiledInstruction) bool {
+	progID := p.allAccountKeys[instr.ProgramIDIndex]
+	if !progID.Equals(solana.TokenProgramID) && !progID.Equals(solana.Token2022ProgramID) {
+		return false
+	}
+	if len(instr.Data) < 9 || len(instr.Accounts) < 3 || !p.accountIndexesValid(instr) {
+		return false
+	}
+	switch instr.Data[0] {
+	case TOKEN_MINT_TO_INSTRUCTION, TOKEN_BURN_INSTRUCTION, TOKEN_MINT_TO_CHECKED_INSTRUCTION, TOKEN_BURN_CHECKED_INSTRUCTION:
+		return true
+	}
+	return false
+}
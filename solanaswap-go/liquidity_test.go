@@ -0,0 +1,99 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestLiquidityDepositWithLPTokens(t *testing.T) {
+	lpMint := testKey("cpmm-pool/lp-mint")
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "cpmm-pool", testTrader, solana.SolMint, 9, testMint, 6)
+	traderLP := testKey("trader/lp")
+	b.tokenAccount(traderLP, testTrader, lpMint, 9, 0, 50)
+	// deposit accounts: owner, authority, pool_state, owner_lp_token, token_0_account, token_1_account, ...
+	b.instruction(RAYDIUM_CPMM_PROGRAM_ID, DEPOSIT_DISCRIMINATOR[:], testTrader, testKey("authority"), pool.address, traderLP, pool.userA, pool.userB)
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 100)
+	b.transfer(0, 2, pool.userB, pool.vaultB, testTrader, 200)
+	b.invoke(0, 2, solana.TokenProgramID, binary.LittleEndian.AppendUint64([]byte{TOKEN_MINT_TO_INSTRUCTION}, 50), lpMint, traderLP, testKey("authority"))
+
+	parser := b.parser(t)
+	events := parser.GetLiquidityEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	want := LiquidityEvent{
+		Signature:   parser.txInfo.Signatures[0],
+		Program:     RAYDIUM_CPMM_PROGRAM_ID,
+		Instruction: "deposit",
+		Action:      LiquidityAdd,
+		Pool:        pool.address,
+		Provider:    testTrader,
+		MintA:       solana.SolMint,
+		AmountA:     100,
+		DecimalsA:   9,
+		MintB:       testMint,
+		AmountB:     200,
+		DecimalsB:   6,
+		LPMint:      lpMint,
+		LPAmount:    50,
+	}
+	if events[0] != want {
+		t.Errorf("got %+v, want %+v", events[0], want)
+	}
+
+	// the deposit's transfers are not a swap
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapDatas) != 0 {
+		t.Errorf("got %d swap data for a deposit, want none", len(swapDatas))
+	}
+}
+
+func TestLiquidityRemoveThroughRouter(t *testing.T) {
+	position := testKey("position")
+	newTx := func(noStackHeights bool) (*testTx, testPool) {
+		b := newTestTx(testTrader)
+		b.noStackHeights = noStackHeights
+		pool := newTestPool(b, "whirlpool", testTrader, solana.SolMint, 9, testMint, 6)
+		// decreaseLiquidity accounts: whirlpool, token_program, position_authority, position, ...
+		b.instruction(DFLOW_PROGRAM_ID, []byte{1}, testTrader)
+		b.invoke(0, 2, ORCA_PROGRAM_ID, DECREASE_LIQUIDITY_DISCRIMINATOR[:], pool.address, solana.TokenProgramID, testTrader, position)
+		b.transfer(0, 3, pool.vaultA, pool.userA, pool.address, 10)
+		b.transfer(0, 3, pool.vaultB, pool.userB, pool.address, 20)
+		return b, pool
+	}
+
+	b, pool := newTx(false)
+	parser := b.parser(t)
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapDatas) != 0 {
+		t.Errorf("got %d swap data for a withdrawal, want none", len(swapDatas))
+	}
+	events := parser.GetLiquidityEvents()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.Instruction != "decreaseLiquidity" || event.Action != LiquidityRemove || !event.Pool.Equals(pool.address) ||
+		!event.Position.Equals(position) || !event.Provider.Equals(testTrader) {
+		t.Errorf("event %s %s of %s position %s by %s, want decreaseLiquidity remove of the whirlpool position by the trader",
+			event.Instruction, event.Action, event.Pool, event.Position, event.Provider)
+	}
+	if event.AmountA != 10 || event.AmountB != 20 || !event.LPMint.IsZero() {
+		t.Errorf("amounts %d/%d LP mint %s, want 10/20 and no LP token", event.AmountA, event.AmountB, event.LPMint)
+	}
+
+	// without stack heights the router's inner instructions can't be attributed
+	b, _ = newTx(true)
+	if events := b.parser(t).GetLiquidityEvents(); len(events) != 0 {
+		t.Errorf("got %d events without stack heights, want none", len(events))
+	}
+}
@@ -0,0 +1,295 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// PriceOracle returns the USD price of one UI unit of a mint at the given time
+type PriceOracle interface {
+	PriceUSD(mint solana.PublicKey, timestamp time.Time) (*big.Rat, error)
+}
+
+// FillValueUSD sets ValueUSD from the oracle price of the swap's input, or of its output when the oracle can't
+// price the input
+func (s *SwapInfo) FillValueUSD(oracle PriceOracle) error {
+	sides := []struct {
+		mint     solana.PublicKey
+		amount   uint64
+		decimals uint8
+	}{
+		{s.TokenInMint, s.TokenInAmount, s.TokenInDecimals},
+		{s.TokenOutMint, s.TokenOutAmount, s.TokenOutDecimals},
+	}
+
+	var lastErr error
+	for _, side := range sides {
+		price, err := oracle.PriceUSD(side.mint, s.Timestamp)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		s.ValueUSD = new(big.Rat).Mul(uiAmount(side.amount, side.decimals), price)
+		return nil
+	}
+	return fmt.Errorf("failed to value swap: %w", lastErr)
+}
+
+// FillValueUSD values every swap of the block with the oracle, swaps it can't price are left without a value
+func (r *BlockResult) FillValueUSD(oracle PriceOracle) {
+	for _, swap := range r.Swaps {
+		_ = swap.FillValueUSD(oracle)
+	}
+}
+
+// uiAmount returns amount / 10^decimals
+func uiAmount(amount uint64, decimals uint8) *big.Rat {
+	return new(big.Rat).SetFrac(new(big.Int).SetUint64(amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+// BlockPriceOracle prices SOL at the volume-weighted price of the USDC/SOL and USDT/SOL swaps of a block and the
+// stablecoins at one dollar, the timestamp is ignored
+type BlockPriceOracle struct {
+	SOL *big.Rat
+}
+
+func NewBlockPriceOracle(swaps []*SwapInfo) (*BlockPriceOracle, error) {
+	dollars, sol := new(big.Rat), new(big.Rat)
+	for _, swap := range swaps {
+		trade, err := swap.Trade()
+		if err != nil || !trade.BaseMint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) || !isStablecoin(trade.QuoteMint) {
+			continue
+		}
+		dollars.Add(dollars, uiAmount(trade.QuoteAmount, trade.QuoteDecimals))
+		sol.Add(sol, uiAmount(trade.BaseAmount, trade.BaseDecimals))
+	}
+	if sol.Sign() == 0 {
+		return nil, fmt.Errorf("no USDC/SOL or USDT/SOL swaps found")
+	}
+	return &BlockPriceOracle{SOL: dollars.Quo(dollars, sol)}, nil
+}
+
+func (o *BlockPriceOracle) PriceUSD(mint solana.PublicKey, _ time.Time) (*big.Rat, error) {
+	switch {
+	case mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID):
+		return new(big.Rat).Set(o.SOL), nil
+	case isStablecoin(mint):
+		return big.NewRat(1, 1), nil
+	}
+	return nil, fmt.Errorf("no block price for %s", mint)
+}
+
+func isStablecoin(mint solana.PublicKey) bool {
+	return mint.Equals(USDC_MINT) || mint.Equals(USDT_MINT)
+}
+
+const (
+	PYTH_MAGIC            = 0xa1b2c3d4
+	PYTH_PRICE_ACCOUNT    = 3
+	PYTH_STATUS_TRADING   = 1
+	PYTH_LEGACY_MIN_SIZE  = 240
+	PYTH_PRICE_UPDATE_MIN = 8 + 32 + 1 + 32 + 8 + 8 + 4 + 8
+)
+
+var PythPriceUpdateV2Discriminator = [8]byte{34, 241, 35, 99, 157, 126, 244, 205}
+
+// PythPrice is a price read from a Pyth price account, the USD price is Price * 10^Expo
+type PythPrice struct {
+	Price       int64
+	Conf        uint64
+	Expo        int32
+	PublishTime time.Time
+}
+
+// Rat returns the price as an exact ratio
+func (p PythPrice) Rat() *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(p.Expo))), nil)
+	price := new(big.Rat).SetInt64(p.Price)
+	if p.Expo < 0 {
+		return price.Quo(price, new(big.Rat).SetInt(scale))
+	}
+	return price.Mul(price, new(big.Rat).SetInt(scale))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ParsePythPriceAccount decodes the data of a legacy Pyth price account or of a PriceUpdateV2 account of the pull
+// oracle, legacy prices are only accepted while the feed is trading
+func ParsePythPriceAccount(data []byte) (PythPrice, error) {
+	if len(data) >= 8 && bytes.Equal(data[:8], PythPriceUpdateV2Discriminator[:]) {
+		return parsePythPriceUpdateV2(data)
+	}
+
+	// magic u32, version u32, type u32, size u32, price_type u32, expo i32, ..., timestamp i64 at 96, ...,
+	// aggregate price i64 at 208, conf u64 at 216, status u32 at 224
+	if len(data) < PYTH_LEGACY_MIN_SIZE {
+		return PythPrice{}, fmt.Errorf("pyth price account has %d bytes", len(data))
+	}
+	if binary.LittleEndian.Uint32(data[0:4]) != PYTH_MAGIC || binary.LittleEndian.Uint32(data[8:12]) != PYTH_PRICE_ACCOUNT {
+		return PythPrice{}, fmt.Errorf("not a pyth price account")
+	}
+	if status := binary.LittleEndian.Uint32(data[224:228]); status != PYTH_STATUS_TRADING {
+		return PythPrice{}, fmt.Errorf("pyth price is not trading (status %d)", status)
+	}
+	return PythPrice{
+		Price:       int64(binary.LittleEndian.Uint64(data[208:216])),
+		Conf:        binary.LittleEndian.Uint64(data[216:224]),
+		Expo:        int32(binary.LittleEndian.Uint32(data[20:24])),
+		PublishTime: time.Unix(int64(binary.LittleEndian.Uint64(data[96:104])), 0),
+	}, nil
+}
+
+// parsePythPriceUpdateV2 decodes discriminator, write_authority, verification_level (Partial{num_signatures u8}
+// or Full) and price_message: feed_id, price i64, conf u64, exponent i32, publish_time i64, ...
+func parsePythPriceUpdateV2(data []byte) (PythPrice, error) {
+	if len(data) < PYTH_PRICE_UPDATE_MIN {
+		return PythPrice{}, fmt.Errorf("pyth price update account has %d bytes", len(data))
+	}
+	offset := 8 + 32
+	if data[offset] == 0 {
+		offset += 2
+	} else {
+		offset++
+	}
+	offset += 32
+	if len(data) < offset+28 {
+		return PythPrice{}, fmt.Errorf("pyth price update account has %d bytes", len(data))
+	}
+	return PythPrice{
+		Price:       int64(binary.LittleEndian.Uint64(data[offset : offset+8])),
+		Conf:        binary.LittleEndian.Uint64(data[offset+8 : offset+16]),
+		Expo:        int32(binary.LittleEndian.Uint32(data[offset+16 : offset+20])),
+		PublishTime: time.Unix(int64(binary.LittleEndian.Uint64(data[offset+20:offset+28])), 0),
+	}, nil
+}
+
+// PythPriceOracle prices mints from Pyth price account snapshots, MaxAge rejects prices published further than
+// that from the swap time (0 accepts any)
+type PythPriceOracle struct {
+	Prices map[solana.PublicKey]PythPrice
+	MaxAge time.Duration
+}
+
+func NewPythPriceOracle() *PythPriceOracle {
+	return &PythPriceOracle{Prices: make(map[solana.PublicKey]PythPrice)}
+}
+
+// AddPriceAccount decodes the snapshot of the USD price account of a mint
+func (o *PythPriceOracle) AddPriceAccount(mint solana.PublicKey, data []byte) error {
+	price, err := ParsePythPriceAccount(data)
+	if err != nil {
+		return fmt.Errorf("error parsing pyth price account of %s: %s", mint, err)
+	}
+	o.Prices[mint] = price
+	return nil
+}
+
+func (o *PythPriceOracle) PriceUSD(mint solana.PublicKey, timestamp time.Time) (*big.Rat, error) {
+	price, ok := o.Prices[mint]
+	if !ok {
+		return nil, fmt.Errorf("no pyth price for %s", mint)
+	}
+	if o.MaxAge > 0 {
+		age := timestamp.Sub(price.PublishTime)
+		if age < 0 {
+			age = -age
+		}
+		if age > o.MaxAge {
+			return nil, fmt.Errorf("pyth price of %s is %s away from the swap", mint, age)
+		}
+	}
+	return price.Rat(), nil
+}
+
+// csvPrice is a price valid from its timestamp until the next one of the same mint
+type csvPrice struct {
+	from  time.Time
+	price *big.Rat
+}
+
+// CSVPriceOracle prices mints from a static table, a price applies from its timestamp until the next one of the
+// same mint
+type CSVPriceOracle struct {
+	prices map[solana.PublicKey][]csvPrice
+}
+
+// NewCSVPriceOracle reads "mint,price" or "mint,timestamp,price" rows, the timestamp being unix seconds or
+// RFC 3339 and the price an exact decimal. A header row and rows without a timestamp, applying at all times, are
+// accepted.
+func NewCSVPriceOracle(r io.Reader) (*CSVPriceOracle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading price csv: %s", err)
+	}
+
+	oracle := &CSVPriceOracle{prices: make(map[solana.PublicKey][]csvPrice)}
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("price csv line %d has %d fields", i+1, len(record))
+		}
+		mint, err := solana.PublicKeyFromBase58(strings.TrimSpace(record[0]))
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("price csv line %d: invalid mint: %s", i+1, err)
+		}
+
+		entry := csvPrice{}
+		if len(record) == 3 {
+			if entry.from, err = parseCSVTime(strings.TrimSpace(record[1])); err != nil {
+				return nil, fmt.Errorf("price csv line %d: %s", i+1, err)
+			}
+		}
+		price, ok := new(big.Rat).SetString(strings.TrimSpace(record[len(record)-1]))
+		if !ok {
+			return nil, fmt.Errorf("price csv line %d: invalid price %q", i+1, record[len(record)-1])
+		}
+		entry.price = price
+		oracle.prices[mint] = append(oracle.prices[mint], entry)
+	}
+
+	for _, prices := range oracle.prices {
+		sort.SliceStable(prices, func(i, j int) bool { return prices[i].from.Before(prices[j].from) })
+	}
+	return oracle, nil
+}
+
+func parseCSVTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return timestamp, nil
+}
+
+func (o *CSVPriceOracle) PriceUSD(mint solana.PublicKey, timestamp time.Time) (*big.Rat, error) {
+	prices := o.prices[mint]
+	// the last price starting at or before the timestamp
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].from.After(timestamp) })
+	if i == 0 {
+		return nil, fmt.Errorf("no csv price for %s at %s", mint, timestamp.Format(time.RFC3339))
+	}
+	return new(big.Rat).Set(prices[i-1].price), nil
+}
@@ -0,0 +1,292 @@
+package solanaswapgo
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return timestamp
+}
+
+func TestCSVPriceOracle(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "oracle", "prices.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	oracle, err := NewCSVPriceOracle(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		mint      solana.PublicKey
+		timestamp string
+		want      string
+		wantErr   bool
+	}{
+		{"before first price", NATIVE_SOL_MINT_PROGRAM_ID, "2024-05-31T23:59:59Z", "", true},
+		{"first price", NATIVE_SOL_MINT_PROGRAM_ID, "2024-06-01T00:00:00Z", "165.42", false},
+		{"between prices", NATIVE_SOL_MINT_PROGRAM_ID, "2024-06-01T00:30:00Z", "165.42", false},
+		{"rfc3339 row", NATIVE_SOL_MINT_PROGRAM_ID, "2024-06-01T01:59:59Z", "166.05", false},
+		{"unix row", NATIVE_SOL_MINT_PROGRAM_ID, "2024-06-01T02:00:00Z", "164.871", false},
+		{"after last price", NATIVE_SOL_MINT_PROGRAM_ID, "2025-01-01T00:00:00Z", "164.871", false},
+		{"static price", USDT_MINT, "2020-01-01T00:00:00Z", "1.0001", false},
+		{"unknown mint", JUPITER_PROGRAM_ID, "2024-06-01T00:00:00Z", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oracle.PriceUSD(tt.mint, mustTime(t, tt.timestamp))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got.FloatString(6))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Cmp(mustRat(t, tt.want)) != 0 {
+				t.Errorf("got %s, want %s", got.FloatString(6), tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVPriceOracleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+	}{
+		{"invalid mint", "mint,price\nSo11111111111111111111111111111111111111112,1\nnot-a-mint,2\n"},
+		{"invalid price", "So11111111111111111111111111111111111111112,abc\n"},
+		{"invalid timestamp", "So11111111111111111111111111111111111111112,yesterday,1\n"},
+		{"too many fields", "So11111111111111111111111111111111111111112,1,2,3\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCSVPriceOracle(strings.NewReader(tt.csv)); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
+
+// legacyPythAccount builds a legacy price account with the given aggregate price, exponent, timestamp and status
+func legacyPythAccount(price int64, expo int32, timestamp int64, status uint32) []byte {
+	data := make([]byte, 3312)
+	binary.LittleEndian.PutUint32(data[0:], PYTH_MAGIC)
+	binary.LittleEndian.PutUint32(data[4:], 2)
+	binary.LittleEndian.PutUint32(data[8:], PYTH_PRICE_ACCOUNT)
+	binary.LittleEndian.PutUint32(data[20:], uint32(expo))
+	binary.LittleEndian.PutUint64(data[96:], uint64(timestamp))
+	binary.LittleEndian.PutUint64(data[208:], uint64(price))
+	binary.LittleEndian.PutUint64(data[216:], 1_000_000)
+	binary.LittleEndian.PutUint32(data[224:], status)
+	return data
+}
+
+// pythPriceUpdateV2 builds a PriceUpdateV2 account, partial verification takes one more byte than full
+func pythPriceUpdateV2(partial bool, price int64, expo int32, publishTime int64) []byte {
+	data := append([]byte{}, PythPriceUpdateV2Discriminator[:]...)
+	data = append(data, make([]byte, 32)...) // write_authority
+	if partial {
+		data = append(data, 0, 3)
+	} else {
+		data = append(data, 1)
+	}
+	data = append(data, make([]byte, 32)...) // feed_id
+	data = binary.LittleEndian.AppendUint64(data, uint64(price))
+	data = binary.LittleEndian.AppendUint64(data, 2_000_000)
+	data = binary.LittleEndian.AppendUint32(data, uint32(expo))
+	data = binary.LittleEndian.AppendUint64(data, uint64(publishTime))
+	data = binary.LittleEndian.AppendUint64(data, uint64(publishTime-1)) // prev_publish_time
+	data = binary.LittleEndian.AppendUint64(data, uint64(price))         // ema_price
+	data = binary.LittleEndian.AppendUint64(data, 2_000_000)             // ema_conf
+	data = binary.LittleEndian.AppendUint64(data, 270_000_000)           // posted_slot
+	return data
+}
+
+func TestParsePythPriceAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		want     string
+		wantConf uint64
+		wantTime int64
+		wantErr  bool
+	}{
+		{"legacy", legacyPythAccount(16542000000, -8, 1717200000, PYTH_STATUS_TRADING), "165.42", 1_000_000, 1717200000, false},
+		{"legacy positive exponent", legacyPythAccount(3, 2, 1717200000, PYTH_STATUS_TRADING), "300", 1_000_000, 1717200000, false},
+		{"legacy not trading", legacyPythAccount(16542000000, -8, 1717200000, 0), "", 0, 0, true},
+		{"legacy bad magic", append([]byte{1, 2, 3, 4}, legacyPythAccount(1, 0, 0, PYTH_STATUS_TRADING)[4:]...), "", 0, 0, true},
+		{"legacy truncated", legacyPythAccount(1, 0, 0, PYTH_STATUS_TRADING)[:200], "", 0, 0, true},
+		{"price update full", pythPriceUpdateV2(false, 16605000000, -8, 1717203600), "166.05", 2_000_000, 1717203600, false},
+		{"price update partial", pythPriceUpdateV2(true, 99995000, -8, 1717203601), "0.99995", 2_000_000, 1717203601, false},
+		{"price update truncated", pythPriceUpdateV2(true, 1, 0, 0)[:100], "", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePythPriceAccount(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Rat().Cmp(mustRat(t, tt.want)) != 0 {
+				t.Errorf("price %s, want %s", got.Rat().FloatString(8), tt.want)
+			}
+			if got.Conf != tt.wantConf {
+				t.Errorf("conf %d, want %d", got.Conf, tt.wantConf)
+			}
+			if got.PublishTime.Unix() != tt.wantTime {
+				t.Errorf("publish time %d, want %d", got.PublishTime.Unix(), tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestPythPriceOracleMaxAge(t *testing.T) {
+	oracle := NewPythPriceOracle()
+	oracle.MaxAge = time.Minute
+	if err := oracle.AddPriceAccount(NATIVE_SOL_MINT_PROGRAM_ID, pythPriceUpdateV2(false, 16605000000, -8, 1717203600)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := oracle.PriceUSD(NATIVE_SOL_MINT_PROGRAM_ID, time.Unix(1717203630, 0)); err != nil {
+		t.Errorf("fresh price rejected: %s", err)
+	}
+	if _, err := oracle.PriceUSD(NATIVE_SOL_MINT_PROGRAM_ID, time.Unix(1717203700, 0)); err == nil {
+		t.Error("stale price accepted")
+	}
+	if _, err := oracle.PriceUSD(USDC_MINT, time.Unix(1717203600, 0)); err == nil {
+		t.Error("price of an unknown mint accepted")
+	}
+}
+
+// the swap of a fetched transaction is valued at its block time
+func TestProcessSwapDataValueAtBlockTime(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "oracle", "prices.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	oracle, err := NewCSVPriceOracle(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := newTestTx(testTrader)
+	pool := newTestPool(b, "solfi-pool", testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, testUSDC, 6)
+	b.instruction(SOLFI_PROGRAM_ID, []byte{7}, pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB)
+	b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 2_000_000_000)
+	b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 330_000_000)
+
+	tx, meta := b.build()
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := new(rpc.TransactionResultEnvelope)
+	if err := envelope.UnmarshalJSON([]byte(`["` + base64.StdEncoding.EncodeToString(raw) + `","base64"]`)); err != nil {
+		t.Fatal(err)
+	}
+	blockTime := solana.UnixTimeSeconds(mustTime(t, "2024-06-01T00:30:00Z").Unix())
+	parser, err := NewTransactionParser(&rpc.GetTransactionResult{Transaction: envelope, Meta: meta, BlockTime: &blockTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.Log.SetOutput(io.Discard)
+	parser.PriceOracle = oracle
+
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapInfo, err := parser.ProcessSwapData(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !swapInfo.Timestamp.Equal(blockTime.Time()) {
+		t.Errorf("timestamp %s, want the block time %s", swapInfo.Timestamp, blockTime.Time())
+	}
+	// 2 SOL at the 165.42 price of the first hour, not the latest price
+	if swapInfo.ValueUSD == nil || swapInfo.ValueUSD.Cmp(mustRat(t, "330.84")) != 0 {
+		t.Errorf("value %v, want 330.84", swapInfo.ValueUSD)
+	}
+}
+
+func TestBlockPriceOracle(t *testing.T) {
+	token := solana.MustPublicKeyFromBase58("CQn88snXCipTxn6DBbwgSA7d9v1sXPmyxzCNNiVNXzFy")
+	swaps := []*SwapInfo{
+		// 150 USDC for 1 SOL
+		{TokenInMint: USDC_MINT, TokenInAmount: 150_000_000, TokenInDecimals: 6, TokenOutMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenOutAmount: 1_000_000_000, TokenOutDecimals: 9},
+		// 2 SOL for 302 USDT
+		{TokenInMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenInAmount: 2_000_000_000, TokenInDecimals: 9, TokenOutMint: USDT_MINT, TokenOutAmount: 302_000_000, TokenOutDecimals: 6},
+		// not a stablecoin pair
+		{TokenInMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenInAmount: 1_000_000_000, TokenInDecimals: 9, TokenOutMint: token, TokenOutAmount: 1, TokenOutDecimals: 6},
+	}
+	oracle, err := NewBlockPriceOracle(swaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mint    solana.PublicKey
+		want    string
+		wantErr bool
+	}{
+		{NATIVE_SOL_MINT_PROGRAM_ID, "452/3", false},
+		{USDC_MINT, "1", false},
+		{USDT_MINT, "1", false},
+		{token, "", true},
+	}
+	for _, tt := range tests {
+		got, err := oracle.PriceUSD(tt.mint, time.Time{})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: want an error", tt.mint)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(mustRat(t, tt.want)) != 0 {
+			t.Errorf("%s: got %s, want %s", tt.mint, got.RatString(), tt.want)
+		}
+	}
+
+	swap := &SwapInfo{TokenInMint: token, TokenInAmount: 5, TokenInDecimals: 0, TokenOutMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenOutAmount: 500_000_000, TokenOutDecimals: 9}
+	if err := swap.FillValueUSD(oracle); err != nil {
+		t.Fatal(err)
+	}
+	if swap.ValueUSD.Cmp(big.NewRat(226, 3)) != 0 {
+		t.Errorf("value %s, want 226/3", swap.ValueUSD.RatString())
+	}
+
+	if _, err := NewBlockPriceOracle(swaps[2:]); err == nil {
+		t.Error("want an error without stablecoin swaps")
+	}
+}
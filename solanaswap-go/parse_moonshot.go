@@ -3,7 +3,6 @@ package solanaswapgo
 import (
 	"bytes"
 	"fmt"
-	"strconv"
 
 	ag_binary "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -95,44 +94,13 @@ func (p *Parser) parseMoonshotTradeInstruction(instruction solana.CompiledInstru
 	}, nil
 }
 
-// getTokenBalanceChanges calculates the balance change for a given token mint for the signer
+// getTokenBalanceChanges calculates the balance change for a given token mint for the signer, SOL being the
+// SOL it traded once the transaction fee and rent are taken out
 func (p *Parser) getTokenBalanceChanges(mint solana.PublicKey) (int64, error) {
-	if mint == NATIVE_SOL_MINT_PROGRAM_ID {
-		// For native SOL, we'll use the first account (index 0) which is typically the fee payer/signer
-		if len(p.txMeta.PostBalances) == 0 || len(p.txMeta.PreBalances) == 0 {
-			return 0, fmt.Errorf("insufficient balance information for SOL")
-		}
-		change := int64(p.txMeta.PostBalances[0]) - int64(p.txMeta.PreBalances[0])
-		return change, nil
-	}
-
-	// Get the signer's public key (assuming it's the first account in the transaction)
-	signer := p.txInfo.Message.AccountKeys[0]
-
-	var preAmount, postAmount int64
-	var balanceFound bool
-
-	for _, preBalance := range p.txMeta.PreTokenBalances {
-		if preBalance.Mint.Equals(mint) && preBalance.Owner.Equals(signer) {
-			preAmount, _ = strconv.ParseInt(preBalance.UiTokenAmount.Amount, 10, 64)
-			balanceFound = true
-			break
-		}
-	}
-
-	for _, postBalance := range p.txMeta.PostTokenBalances {
-		if postBalance.Mint.Equals(mint) && postBalance.Owner.Equals(signer) {
-			postAmount, _ = strconv.ParseInt(postBalance.UiTokenAmount.Amount, 10, 64)
-			balanceFound = true
-			break
-		}
-	}
-
-	if !balanceFound {
+	change, ok := p.getTradeBalanceChanges(p.getSigner())[mint]
+	if !ok {
 		return 0, fmt.Errorf("could not find balance for specified mint and signer")
 	}
-
-	change := postAmount - preAmount
 	return change, nil
 }
 
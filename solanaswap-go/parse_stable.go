@@ -0,0 +1,210 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	LIFINITY_SWAP_DISCRIMINATOR = [8]byte{248, 198, 158, 145, 225, 117, 135, 200}
+	STABBLE_SWAP_DISCRIMINATOR  = [8]byte{248, 198, 158, 145, 225, 117, 135, 200}
+	OBRIC_SWAP_DISCRIMINATOR    = [8]byte{248, 198, 158, 145, 225, 117, 135, 200}
+)
+
+const (
+	SABER_SWAP_INSTRUCTION             = 1
+	SANCTUM_SWAP_EXACT_IN_INSTRUCTION  = 1
+	SANCTUM_SWAP_EXACT_OUT_INSTRUCTION = 2
+)
+
+// stableSwapPrograms maps the stable-swap and oracle AMMs decoded by processStableSwaps to their swap type
+var stableSwapPrograms = map[solana.PublicKey]SwapType{
+	LIFINITY_V2_PROGRAM_ID:           LIFINITY,
+	SABER_STABLE_SWAP_PROGRAM_ID:     SABER,
+	STABBLE_STABLE_SWAP_PROGRAM_ID:   STABBLE,
+	STABBLE_WEIGHTED_SWAP_PROGRAM_ID: STABBLE,
+	SANCTUM_INFINITY_PROGRAM_ID:      SANCTUM,
+	OBRIC_V2_PROGRAM_ID:              OBRIC,
+}
+
+// PoolSwapInstruction is the pool and direction decoded from a stable-swap or oracle AMM swap instruction,
+// amounts are the instruction arguments, the executed amounts come from the accompanying transfers
+type PoolSwapInstruction struct {
+	Program          solana.PublicKey
+	Pool             solana.PublicKey
+	InputMint        solana.PublicKey
+	OutputMint       solana.PublicKey
+	AmountIn         uint64
+	MinimumAmountOut uint64
+	ExactOut         bool
+}
+
+func isStableSwapProgram(progID solana.PublicKey) bool {
+	_, ok := stableSwapPrograms[progID]
+	return ok
+}
+
+// processStableSwaps decodes the swap instructions of a stable-swap or oracle AMM along with their token transfers
+func (p *Parser) processStableSwaps(instructionIndex int, programID solana.PublicKey) []SwapData {
+	swapType := stableSwapPrograms[programID]
+
+	var candidates []solana.CompiledInstruction
+	if instructionIndex < len(p.txInfo.Message.Instructions) {
+		candidates = append(candidates, p.txInfo.Message.Instructions[instructionIndex])
+	}
+	candidates = append(candidates, p.getInnerInstructions(instructionIndex)...)
+
+	var swaps []SwapData
+	for _, candidate := range candidates {
+		if !p.allAccountKeys[candidate.ProgramIDIndex].Equals(programID) {
+			continue
+		}
+		instruction, err := p.parsePoolSwapInstruction(candidate)
+		if err != nil {
+			p.Log.Errorf("error decoding %s swap instruction: %s", swapType, err)
+			continue
+		}
+		if instruction != nil {
+			swaps = append(swaps, SwapData{Type: swapType, Data: instruction})
+		}
+	}
+
+	return append(swaps, p.processVenueTransfers(instructionIndex, swapType, programID)...)
+}
+
+// parsePoolSwapInstruction decodes the pool and direction of a swap instruction, returning nil for other instructions
+func (p *Parser) parsePoolSwapInstruction(instruction solana.CompiledInstruction) (*PoolSwapInstruction, error) {
+	decodedBytes, err := base58.Decode(instruction.Data.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base58 instruction data: %v", err)
+	}
+
+	programID := p.allAccountKeys[instruction.ProgramIDIndex]
+	account := func(i int) solana.PublicKey {
+		return p.allAccountKeys[instruction.Accounts[i]]
+	}
+	tokenAccountMint := func(i int) solana.PublicKey {
+		mint, err := solana.PublicKeyFromBase58(p.splTokenInfoMap[account(i).String()].Mint)
+		if err != nil {
+			return solana.PublicKey{}
+		}
+		return mint
+	}
+
+	switch {
+	case programID.Equals(LIFINITY_V2_PROGRAM_ID):
+		// accounts: authority, amm, user_transfer_authority, source_info, destination_info, swap_source, swap_destination, ...
+		if len(decodedBytes) < 24 || !bytes.Equal(decodedBytes[:8], LIFINITY_SWAP_DISCRIMINATOR[:]) {
+			return nil, nil
+		}
+		if len(instruction.Accounts) < 7 {
+			return nil, fmt.Errorf("lifinity swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		return &PoolSwapInstruction{
+			Program:          programID,
+			Pool:             account(1),
+			InputMint:        tokenAccountMint(3),
+			OutputMint:       tokenAccountMint(4),
+			AmountIn:         binary.LittleEndian.Uint64(decodedBytes[8:16]),
+			MinimumAmountOut: binary.LittleEndian.Uint64(decodedBytes[16:24]),
+		}, nil
+
+	case programID.Equals(SABER_STABLE_SWAP_PROGRAM_ID):
+		// accounts: swap, swap_authority, user_authority, source, swap_source, swap_destination, destination, ...
+		if len(decodedBytes) < 17 || decodedBytes[0] != SABER_SWAP_INSTRUCTION {
+			return nil, nil
+		}
+		if len(instruction.Accounts) < 7 {
+			return nil, fmt.Errorf("saber swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		return &PoolSwapInstruction{
+			Program:          programID,
+			Pool:             account(0),
+			InputMint:        tokenAccountMint(3),
+			OutputMint:       tokenAccountMint(6),
+			AmountIn:         binary.LittleEndian.Uint64(decodedBytes[1:9]),
+			MinimumAmountOut: binary.LittleEndian.Uint64(decodedBytes[9:17]),
+		}, nil
+
+	case programID.Equals(STABBLE_STABLE_SWAP_PROGRAM_ID) || programID.Equals(STABBLE_WEIGHTED_SWAP_PROGRAM_ID):
+		// accounts: user, user_token_in, user_token_out, vault_token_in, vault_token_out, beneficiary_token_out, pool, ...
+		// args: amount_in Option<u64>, minimum_amount_out u64
+		if len(decodedBytes) < 9 || !bytes.Equal(decodedBytes[:8], STABBLE_SWAP_DISCRIMINATOR[:]) {
+			return nil, nil
+		}
+		if len(instruction.Accounts) < 7 {
+			return nil, fmt.Errorf("stabble swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		swap := &PoolSwapInstruction{
+			Program:    programID,
+			Pool:       account(6),
+			InputMint:  tokenAccountMint(1),
+			OutputMint: tokenAccountMint(2),
+		}
+		args := decodedBytes[9:]
+		if decodedBytes[8] == 1 && len(args) >= 8 {
+			swap.AmountIn = binary.LittleEndian.Uint64(args[:8])
+			args = args[8:]
+		}
+		if len(args) >= 8 {
+			swap.MinimumAmountOut = binary.LittleEndian.Uint64(args[:8])
+		}
+		return swap, nil
+
+	case programID.Equals(SANCTUM_INFINITY_PROGRAM_ID):
+		// accounts: signer, src_lst_mint, dst_lst_mint, src_lst_acc, dst_lst_acc, protocol_fee_accumulator,
+		// src_lst_token_program, dst_lst_token_program, pool_state, ...
+		if len(decodedBytes) < 27 ||
+			(decodedBytes[0] != SANCTUM_SWAP_EXACT_IN_INSTRUCTION && decodedBytes[0] != SANCTUM_SWAP_EXACT_OUT_INSTRUCTION) {
+			return nil, nil
+		}
+		if len(instruction.Accounts) < 9 {
+			return nil, fmt.Errorf("sanctum swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		// args: src_lst_value_calc_accs u8, dst_lst_value_calc_accs u8, src_lst_index u32, dst_lst_index u32, limit u64, amount u64
+		limit := binary.LittleEndian.Uint64(decodedBytes[11:19])
+		amount := binary.LittleEndian.Uint64(decodedBytes[19:27])
+		swap := &PoolSwapInstruction{
+			Program:    programID,
+			Pool:       account(8),
+			InputMint:  account(1),
+			OutputMint: account(2),
+			ExactOut:   decodedBytes[0] == SANCTUM_SWAP_EXACT_OUT_INSTRUCTION,
+		}
+		if swap.ExactOut {
+			swap.AmountIn = limit
+		} else {
+			swap.AmountIn = amount
+			swap.MinimumAmountOut = limit
+		}
+		return swap, nil
+
+	case programID.Equals(OBRIC_V2_PROGRAM_ID):
+		// accounts: trading_pair, mint_x, mint_y, reserve_x, reserve_y, user_token_account_x, user_token_account_y, ...
+		// args: is_x_to_y bool, input_amt u64, min_output_amt u64
+		if len(decodedBytes) < 25 || !bytes.Equal(decodedBytes[:8], OBRIC_SWAP_DISCRIMINATOR[:]) {
+			return nil, nil
+		}
+		if len(instruction.Accounts) < 3 {
+			return nil, fmt.Errorf("obric swap instruction has %d accounts", len(instruction.Accounts))
+		}
+		swap := &PoolSwapInstruction{
+			Program:          programID,
+			Pool:             account(0),
+			InputMint:        account(1),
+			OutputMint:       account(2),
+			AmountIn:         binary.LittleEndian.Uint64(decodedBytes[9:17]),
+			MinimumAmountOut: binary.LittleEndian.Uint64(decodedBytes[17:25]),
+		}
+		if decodedBytes[8] == 0 {
+			swap.InputMint, swap.OutputMint = swap.OutputMint, swap.InputMint
+		}
+		return swap, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,133 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestStableSwapInstructions(t *testing.T) {
+	u64 := func(data []byte, values ...uint64) []byte {
+		for _, value := range values {
+			data = binary.LittleEndian.AppendUint64(data, value)
+		}
+		return data
+	}
+	feeAccount := testKey("fee-account")
+
+	tests := []struct {
+		name     string
+		program  solana.PublicKey
+		swapType SwapType
+		data     []byte
+		accounts func(pool testPool) []solana.PublicKey
+		// aToB is the direction of the trade, the pool account and the decoded arguments follow
+		aToB     bool
+		amountIn uint64
+		minOut   uint64
+		exactOut bool
+	}{
+		{
+			name:     "lifinity",
+			program:  LIFINITY_V2_PROGRAM_ID,
+			swapType: LIFINITY,
+			data:     u64(LIFINITY_SWAP_DISCRIMINATOR[:], 1_000_000, 990_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{testKey("authority"), pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB}
+			},
+			aToB:     true,
+			amountIn: 1_000_000,
+			minOut:   990_000,
+		},
+		{
+			name:     "saber",
+			program:  SABER_STABLE_SWAP_PROGRAM_ID,
+			swapType: SABER,
+			data:     u64([]byte{SABER_SWAP_INSTRUCTION}, 1_000_000, 999_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{pool.address, testKey("authority"), testTrader, pool.userB, pool.vaultB, pool.vaultA, pool.userA}
+			},
+			amountIn: 1_000_000,
+			minOut:   999_000,
+		},
+		{
+			name:     "stabble exact amount in",
+			program:  STABBLE_STABLE_SWAP_PROGRAM_ID,
+			swapType: STABBLE,
+			data:     u64(append(STABBLE_SWAP_DISCRIMINATOR[:], 1), 1_000_000, 998_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB, feeAccount, pool.address}
+			},
+			aToB:     true,
+			amountIn: 1_000_000,
+			minOut:   998_000,
+		},
+		{
+			name:     "stabble whole balance",
+			program:  STABBLE_WEIGHTED_SWAP_PROGRAM_ID,
+			swapType: STABBLE,
+			data:     u64(append(STABBLE_SWAP_DISCRIMINATOR[:], 0), 997_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{testTrader, pool.userB, pool.userA, pool.vaultB, pool.vaultA, feeAccount, pool.address}
+			},
+			minOut: 997_000,
+		},
+		{
+			name:     "sanctum exact out",
+			program:  SANCTUM_INFINITY_PROGRAM_ID,
+			swapType: SANCTUM,
+			data:     u64([]byte{SANCTUM_SWAP_EXACT_OUT_INSTRUCTION, 1, 1, 0, 0, 0, 0, 1, 0, 0, 0}, 1_010_000, 1_000_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{testTrader, pool.mintA, pool.mintB, pool.userA, pool.userB, feeAccount,
+					solana.TokenProgramID, solana.TokenProgramID, pool.address}
+			},
+			aToB:     true,
+			amountIn: 1_010_000,
+			exactOut: true,
+		},
+		{
+			name:     "obric y to x",
+			program:  OBRIC_V2_PROGRAM_ID,
+			swapType: OBRIC,
+			data:     u64(append(OBRIC_SWAP_DISCRIMINATOR[:], 0), 1_000_000, 996_000),
+			accounts: func(pool testPool) []solana.PublicKey {
+				return []solana.PublicKey{pool.address, pool.mintA, pool.mintB, pool.vaultA, pool.vaultB, pool.userA, pool.userB}
+			},
+			amountIn: 1_000_000,
+			minOut:   996_000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestTx(testTrader)
+			pool := newTestPool(b, tt.name, testTrader, testUSDC, 6, testUSDT, 6)
+			inMint, outMint := pool.mintB, pool.mintA
+			userIn, vaultIn, vaultOut, userOut := pool.userB, pool.vaultB, pool.vaultA, pool.userA
+			if tt.aToB {
+				inMint, outMint = pool.mintA, pool.mintB
+				userIn, vaultIn, vaultOut, userOut = pool.userA, pool.vaultA, pool.vaultB, pool.userB
+			}
+
+			b.instruction(tt.program, tt.data, tt.accounts(pool)...)
+			b.transfer(0, 2, userIn, vaultIn, testTrader, 1_000_000)
+			b.transfer(0, 2, vaultOut, userOut, pool.address, 999_500)
+
+			_, swapDatas, swapInfo := b.parse(t)
+			instruction := singleSwap[*PoolSwapInstruction](t, swapDatas)
+			if !instruction.Program.Equals(tt.program) || !instruction.Pool.Equals(pool.address) {
+				t.Errorf("program %s pool %s", instruction.Program, instruction.Pool)
+			}
+			if !instruction.InputMint.Equals(inMint) || !instruction.OutputMint.Equals(outMint) {
+				t.Errorf("decoded %s to %s, want %s to %s", instruction.InputMint, instruction.OutputMint, inMint, outMint)
+			}
+			if instruction.AmountIn != tt.amountIn || instruction.MinimumAmountOut != tt.minOut || instruction.ExactOut != tt.exactOut {
+				t.Errorf("amount in %d minimum out %d exact out %v", instruction.AmountIn, instruction.MinimumAmountOut, instruction.ExactOut)
+			}
+			if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(tt.swapType) {
+				t.Errorf("AMMs %v, want %s", swapInfo.AMMs, tt.swapType)
+			}
+			assertSwap(t, swapInfo, inMint, 1_000_000, outMint, 999_500)
+		})
+	}
+}
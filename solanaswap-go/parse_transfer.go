@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 )
 
 type TransferInfo struct {
@@ -26,43 +27,21 @@ type TokenInfo struct {
 }
 
 func (p *Parser) processRaydSwaps(instructionIndex int) []SwapData {
-	var swaps []SwapData
-	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
-		if innerInstructionSet.Index == uint16(instructionIndex) {
-			for _, innerInstruction := range innerInstructionSet.Instructions {
-				switch {
-				case p.isTransfer(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransfer(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: RAYDIUM, Data: transfer})
-					}
-				case p.isTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: RAYDIUM, Data: transfer})
-					}
-				}
-			}
-		}
-	}
-	return swaps
+	return p.processVenueTransfers(instructionIndex, RAYDIUM,
+		RAYDIUM_V4_PROGRAM_ID,
+		RAYDIUM_AMM_PROGRAM_ID,
+		RAYDIUM_LAUNCHLAB_PROGRAM_ID,
+		RAYDIUM_AP51_PROGRAM_ID,
+	)
 }
 
-func (p *Parser) processOrcaSwaps(instructionIndex int) []SwapData {
-	var swaps []SwapData
-	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
-		if innerInstructionSet.Index == uint16(instructionIndex) {
-			for _, innerInstruction := range innerInstructionSet.Instructions {
-				if p.isTransfer(p.convertRPCToSolanaInstruction(innerInstruction)) {
-					transfer := p.processTransfer(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: ORCA, Data: transfer})
-					}
-				}
-			}
-		}
-	}
-	return swaps
+func (p *Parser) processOrcaTransfers(instructionIndex int) []SwapData {
+	return p.processVenueTransfers(instructionIndex, ORCA, ORCA_PROGRAM_ID)
+}
+
+// processPropAMMSwaps collects the token transfers of a proprietary AMM, these programs publish no IDL or events
+func (p *Parser) processPropAMMSwaps(instructionIndex int, programID solana.PublicKey) []SwapData {
+	return p.processVenueTransfers(instructionIndex, propAMMPrograms[programID], programID)
 }
 
 func (p *Parser) processTransfer(instr solana.CompiledInstruction) *TransferData {
@@ -89,13 +68,18 @@ func (p *Parser) processTransfer(instr solana.CompiledInstruction) *TransferData
 
 func (p *Parser) extractSPLTokenInfo() error {
 	splTokenAddresses := make(map[string]TokenInfo)
-
-	for _, accountInfo := range p.txMeta.PostTokenBalances {
-		if !accountInfo.Mint.IsZero() {
-			accountKey := p.allAccountKeys[accountInfo.AccountIndex].String()
-			splTokenAddresses[accountKey] = TokenInfo{
-				Mint:     accountInfo.Mint.String(),
-				Decimals: accountInfo.UiTokenAmount.Decimals,
+	mintDecimals := make(map[string]uint8)
+
+	// accounts closed by the transaction only appear in the pre balances
+	for _, balances := range [][]rpc.TokenBalance{p.txMeta.PreTokenBalances, p.txMeta.PostTokenBalances} {
+		for _, accountInfo := range balances {
+			if !accountInfo.Mint.IsZero() && int(accountInfo.AccountIndex) < len(p.allAccountKeys) {
+				accountKey := p.allAccountKeys[accountInfo.AccountIndex].String()
+				splTokenAddresses[accountKey] = TokenInfo{
+					Mint:     accountInfo.Mint.String(),
+					Decimals: accountInfo.UiTokenAmount.Decimals,
+				}
+				mintDecimals[accountInfo.Mint.String()] = accountInfo.UiTokenAmount.Decimals
 			}
 		}
 	}
@@ -133,12 +117,23 @@ func (p *Parser) extractSPLTokenInfo() error {
 		}
 	}
 
+	// token accounts created and closed within the transaction have no balances, take their mint from the
+	// instruction that initialized them and only assume wSOL when it is not part of the transaction
+	initializedMints := p.getInitializedTokenAccountMints()
 	for account, info := range splTokenAddresses {
-		if info.Mint == "" {
+		if info.Mint != "" {
+			continue
+		}
+		if mint, ok := initializedMints[account]; ok && !mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
 			splTokenAddresses[account] = TokenInfo{
-				Mint:     NATIVE_SOL_MINT_PROGRAM_ID.String(),
-				Decimals: 9, // Native SOL has 9 decimal places
+				Mint:     mint.String(),
+				Decimals: mintDecimals[mint.String()],
 			}
+			continue
+		}
+		splTokenAddresses[account] = TokenInfo{
+			Mint:     NATIVE_SOL_MINT_PROGRAM_ID.String(),
+			Decimals: 9, // Native SOL has 9 decimal places
 		}
 	}
 
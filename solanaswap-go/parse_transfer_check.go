@@ -26,26 +26,7 @@ type TransferCheck struct {
 }
 
 func (p *Parser) processMeteoraSwaps(instructionIndex int) []SwapData {
-	var swaps []SwapData
-	for _, innerInstructionSet := range p.txMeta.InnerInstructions {
-		if innerInstructionSet.Index == uint16(instructionIndex) {
-			for _, innerInstruction := range innerInstructionSet.Instructions {
-				switch {
-				case p.isTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransferCheck(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: METEORA, Data: transfer})
-					}
-				case p.isTransfer(p.convertRPCToSolanaInstruction(innerInstruction)):
-					transfer := p.processTransfer(p.convertRPCToSolanaInstruction(innerInstruction))
-					if transfer != nil {
-						swaps = append(swaps, SwapData{Type: METEORA, Data: transfer})
-					}
-				}
-			}
-		}
-	}
-	return swaps
+	return p.processVenueTransfers(instructionIndex, METEORA, METEORA_POOLS_PROGRAM_ID, METEORA_DLMM_PROGRAM_ID)
 }
 
 func (p *Parser) processTransferCheck(instr solana.CompiledInstruction) *TransferCheck {
@@ -0,0 +1,54 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestPropAMMTransfers(t *testing.T) {
+	tests := []struct {
+		program  solana.PublicKey
+		swapType SwapType
+		label    string
+	}{
+		{SOLFI_PROGRAM_ID, SOLFI, "SolFi"},
+		{HUMIDIFI_PROGRAM_ID, HUMIDIFI, "HumidiFi"},
+		{TESSERA_PROGRAM_ID, TESSERA, "Tessera V"},
+		{ZEROFI_PROGRAM_ID, ZEROFI, "ZeroFi"},
+		{GOONFI_PROGRAM_ID, GOONFI, "GoonFi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			b := newTestTx(testTrader)
+			pool := newTestPool(b, "prop-amm", testTrader, solana.SolMint, 9, testMint, 6)
+			// the instruction data is private to each market maker, the trade is read from its vault transfers
+			b.instruction(tt.program, []byte{7, 1, 2, 3}, pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB)
+			b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 2_000_000_000)
+			b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 300_000_000)
+			// a transfer of the trader's own outside the market maker is not part of the trade
+			b.instruction(solana.TokenProgramID, tokenTransferData(5_000), pool.userB, testKey("friend"), testTrader)
+
+			_, swapDatas, swapInfo := b.parse(t)
+			for _, swapData := range swapDatas {
+				if swapData.Type != tt.swapType {
+					t.Errorf("swap data type %s, want %s", swapData.Type, tt.swapType)
+				}
+			}
+			if len(swapDatas) != 2 {
+				t.Fatalf("got %d swap data, want 2", len(swapDatas))
+			}
+			assertSwap(t, swapInfo, solana.SolMint, 2_000_000_000, testMint, 300_000_000)
+			if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(tt.swapType) {
+				t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, tt.swapType)
+			}
+			if label := GetProgramLabel(tt.program); label != tt.label {
+				t.Errorf("label %q, want %q", label, tt.label)
+			}
+		})
+	}
+
+	if label := GetProgramLabel(testKey("unknown")); label != testKey("unknown").String() {
+		t.Errorf("label of an unknown program %q, want its address", label)
+	}
+}
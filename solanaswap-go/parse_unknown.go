@@ -0,0 +1,116 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// nonSwapPrograms are the programs a transaction calls around its swaps, they are never reported as unknown swaps
+var nonSwapPrograms = map[solana.PublicKey]bool{
+	solana.SystemProgramID:                    true,
+	solana.ComputeBudget:                      true,
+	solana.TokenProgramID:                     true,
+	solana.Token2022ProgramID:                 true,
+	solana.SPLAssociatedTokenAccountProgramID: true,
+	solana.MemoProgramID:                      true,
+	JUPITER_DCA_PROGRAM_ID:                    true,
+	MOONSHOT_PROGRAM_ID:                       true,
+	OKX_DEX_ROUTER_PROGRAM_ID:                 true,
+}
+
+// UnknownSwapData is a swap found by its token movements in an instruction of a program without a decoder: one mint
+// left accounts owned by the signer and a different one arrived at accounts the same signer owns
+type UnknownSwapData struct {
+	Program        solana.PublicKey
+	Owner          solana.PublicKey
+	InputMint      solana.PublicKey
+	InputAmount    uint64
+	InputDecimals  uint8
+	OutputMint     solana.PublicKey
+	OutputAmount   uint64
+	OutputDecimals uint8
+}
+
+func isUnknownSwapCandidate(progID solana.PublicKey) bool {
+	return !nonSwapPrograms[progID] && !isRouterProgram(progID)
+}
+
+// processUnknownSwaps looks for the token movements of a swap in the inner instructions of an unrecognised program
+func (p *Parser) processUnknownSwaps(instructionIndex int, programID solana.PublicKey) []SwapData {
+	owner := p.getSigner()
+
+	sent := make(map[solana.PublicKey]uint64)
+	received := make(map[solana.PublicKey]uint64)
+	for _, instr := range p.getInnerInstructions(instructionIndex) {
+		var source, destination, mint solana.PublicKey
+		var amount uint64
+		switch {
+		case p.isTransfer(instr):
+			source, destination = p.allAccountKeys[instr.Accounts[0]], p.allAccountKeys[instr.Accounts[1]]
+			if p.isOwnedBy(source, owner) {
+				mint, _ = p.tokenAccountMint(source)
+			} else {
+				mint, _ = p.tokenAccountMint(destination)
+			}
+			amount = binary.LittleEndian.Uint64(instr.Data[1:9])
+		case p.isTransferCheck(instr):
+			source, destination = p.allAccountKeys[instr.Accounts[0]], p.allAccountKeys[instr.Accounts[2]]
+			mint = p.allAccountKeys[instr.Accounts[1]]
+			amount = binary.LittleEndian.Uint64(instr.Data[1:9])
+		case p.isSystemTransfer(instr):
+			source, destination = p.allAccountKeys[instr.Accounts[0]], p.allAccountKeys[instr.Accounts[1]]
+			if _, isFee := p.botFeeWallet(destination); isFee || isJitoTipAccount(destination) {
+				continue
+			}
+			mint = NATIVE_SOL_MINT_PROGRAM_ID
+			amount = binary.LittleEndian.Uint64(instr.Data[4:12])
+		default:
+			continue
+		}
+		if mint.IsZero() {
+			continue
+		}
+
+		fromOwner, toOwner := p.isOwnedBy(source, owner), p.isOwnedBy(destination, owner)
+		switch {
+		case fromOwner && !toOwner:
+			sent[mint] += amount
+		case toOwner && !fromOwner:
+			received[mint] += amount
+		}
+	}
+
+	if len(sent) != 1 || len(received) != 1 {
+		return nil
+	}
+	swap := &UnknownSwapData{Program: programID, Owner: owner}
+	for mint, amount := range sent {
+		swap.InputMint, swap.InputAmount = mint, amount
+	}
+	for mint, amount := range received {
+		swap.OutputMint, swap.OutputAmount = mint, amount
+	}
+	if swap.InputMint.Equals(swap.OutputMint) {
+		return nil
+	}
+	swap.InputDecimals = p.splDecimalsMap[swap.InputMint.String()]
+	swap.OutputDecimals = p.splDecimalsMap[swap.OutputMint.String()]
+
+	return []SwapData{{Type: UNKNOWN, Data: swap}}
+}
+
+// GetUnknownPrograms returns the programs of the unknown swaps in the given swap data, sorted
+func GetUnknownPrograms(swapDatas []SwapData) []solana.PublicKey {
+	seen := make(map[solana.PublicKey]bool)
+	var programs []solana.PublicKey
+	for _, swapData := range swapDatas {
+		if data, ok := swapData.Data.(*UnknownSwapData); ok && !seen[data.Program] {
+			seen[data.Program] = true
+			programs = append(programs, data.Program)
+		}
+	}
+	sort.Slice(programs, func(i, j int) bool { return programs[i].String() < programs[j].String() })
+	return programs
+}
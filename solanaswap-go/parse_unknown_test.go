@@ -0,0 +1,106 @@
+package solanaswapgo
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+var testUnknownProgram = testKey("unknown-program")
+
+// unknownSwapTx calls a program without a decoder which moves the trader's tokens through vaults without token
+// balances, so each mint can only be read from the trader's side of the transfer. The trader also pays a bot fee
+// and a Jito tip inside the call.
+func unknownSwapTx(received ...solana.PublicKey) *testTx {
+	b := newTestTx(testTrader)
+	traderUSDC, traderMint := testKey("trader/usdc"), testKey("trader/mint")
+	b.tokenAccount(traderUSDC, testTrader, testUSDC, 6, 100_000, 0)
+	b.tokenAccount(traderMint, testTrader, testMint, 9, 0, 5_000)
+
+	b.instruction(testUnknownProgram, []byte{1}, testTrader, traderUSDC, traderMint)
+	b.transfer(0, 2, traderUSDC, testKey("unknown/vault-a"), testTrader, 100_000)
+	b.transfer(0, 2, testKey("unknown/vault-b"), traderMint, testKey("unknown"), 5_000)
+	for i, account := range received {
+		b.transfer(0, 2, testKey("unknown/vault-c"), account, testKey("unknown"), uint64(1_000*(i+1)))
+	}
+	b.invoke(0, 2, solana.SystemProgramID, systemTransferData(1_000_000), testTrader, TROJAN_FEE_WALLET)
+	b.invoke(0, 2, solana.SystemProgramID, systemTransferData(10_000), testTrader, JitoTipAccounts[0])
+	return b
+}
+
+func TestUnknownSwap(t *testing.T) {
+	parser := unknownSwapTx().parser(t)
+	parser.DetectUnknownSwaps = true
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap := singleSwap[*UnknownSwapData](t, swapDatas)
+	want := UnknownSwapData{
+		Program:        testUnknownProgram,
+		Owner:          testTrader,
+		InputMint:      testUSDC,
+		InputAmount:    100_000,
+		InputDecimals:  6,
+		OutputMint:     testMint,
+		OutputAmount:   5_000,
+		OutputDecimals: 9,
+	}
+	if *swap != want {
+		t.Errorf("got %+v, want %+v", *swap, want)
+	}
+	if programs := GetUnknownPrograms(swapDatas); len(programs) != 1 || !programs[0].Equals(testUnknownProgram) {
+		t.Errorf("unknown programs %v, want [%s]", programs, testUnknownProgram)
+	}
+
+	swapInfo, err := parser.ProcessSwapData(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSwap(t, swapInfo, testUSDC, 100_000, testMint, 5_000)
+	if len(swapInfo.AMMs) != 1 || swapInfo.AMMs[0] != string(UNKNOWN) {
+		t.Errorf("AMMs %v, want [%s]", swapInfo.AMMs, UNKNOWN)
+	}
+}
+
+func TestUnknownSwapDisabled(t *testing.T) {
+	parser := unknownSwapTx().parser(t)
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(swapDatas) != 0 {
+		t.Fatalf("got %d swap data without DetectUnknownSwaps, want none", len(swapDatas))
+	}
+	if programs := GetUnknownPrograms(swapDatas); len(programs) != 0 {
+		t.Errorf("unknown programs %v, want none", programs)
+	}
+}
+
+func TestUnknownSwapRejected(t *testing.T) {
+	// a second mint arriving at the trader
+	other := testKey("other-mint")
+	twoOut := unknownSwapTx(testKey("trader/other"))
+	twoOut.tokenAccount(testKey("trader/other"), testTrader, other, 6, 0, 1_000)
+
+	// the trader gets back the mint they sent
+	sameMint := newTestTx(testTrader)
+	sameMint.tokenAccount(testKey("trader/usdc"), testTrader, testUSDC, 6, 100_000, 90_000)
+	sameMint.instruction(testUnknownProgram, []byte{1}, testTrader, testKey("trader/usdc"))
+	sameMint.transfer(0, 2, testKey("trader/usdc"), testKey("unknown/vault-a"), testTrader, 100_000)
+	sameMint.transfer(0, 2, testKey("unknown/vault-a"), testKey("trader/usdc"), testKey("unknown"), 90_000)
+
+	for name, b := range map[string]*testTx{"two mints out": twoOut, "same mint": sameMint} {
+		t.Run(name, func(t *testing.T) {
+			parser := b.parser(t)
+			parser.DetectUnknownSwaps = true
+			swapDatas, err := parser.ParseTransaction()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(swapDatas) != 0 {
+				t.Errorf("got %d swap data, want none", len(swapDatas))
+			}
+		})
+	}
+}
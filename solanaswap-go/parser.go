@@ -2,6 +2,7 @@ package solanaswapgo
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 	"time"
 
@@ -11,10 +12,18 @@ import (
 )
 
 const (
-	PROTOCOL_RAYDIUM = "raydium"
-	PROTOCOL_ORCA    = "orca"
-	PROTOCOL_METEORA = "meteora"
-	PROTOCOL_PUMPFUN = "pumpfun"
+	PROTOCOL_RAYDIUM         = "raydium"
+	PROTOCOL_RAYDIUM_CLMM    = "raydium_clmm"
+	PROTOCOL_RAYDIUM_CPMM    = "raydium_cpmm"
+	PROTOCOL_ORCA            = "orca"
+	PROTOCOL_METEORA         = "meteora"
+	PROTOCOL_METEORA_DLMM    = "meteora_dlmm"
+	PROTOCOL_METEORA_DAMM_V2 = "meteora_damm_v2"
+	PROTOCOL_METEORA_DBC     = "meteora_dbc"
+	PROTOCOL_PUMPFUN         = "pumpfun"
+	PROTOCOL_PUMPSWAP        = "pumpswap"
+	PROTOCOL_OPENBOOK        = "openbook"
+	PROTOCOL_SERUM           = "serum"
 )
 
 type TokenTransfer struct {
@@ -29,7 +38,31 @@ type Parser struct {
 	allAccountKeys  solana.PublicKeySlice
 	splTokenInfoMap map[string]TokenInfo
 	splDecimalsMap  map[string]uint8
+	callTree        []*InstructionNode
+	scope           *InstructionNode
+	blockTime       time.Time
 	Log             *logrus.Logger
+
+	// BotFeeWallets maps the fee wallets of trading bots to the bot name, transfers to them are reported as bot
+	// fees rather than swap amounts. It starts with the FeeWallets of the Routers catalogue, add, replace or
+	// delete entries to override them for this parser.
+	BotFeeWallets map[solana.PublicKey]string
+
+	// RaydiumCpmmFeeRates maps Raydium CPMM AmmConfig accounts to their fee rates, used to split the trade fee of
+	// each swap into its protocol and fund parts. It defaults to the public AmmConfig accounts, add or override the
+	// configs you trade against.
+	RaydiumCpmmFeeRates map[solana.PublicKey]RaydiumCpmmFeeRate
+
+	// DetectUnknownSwaps enables the heuristic detection of swaps through programs without a decoder, reported
+	// with the UNKNOWN swap type
+	DetectUnknownSwaps bool
+
+	// DetectBalanceSwaps enables the fallback to the signer's balance changes for transactions without decoded
+	// swaps, reported with the UNKNOWN swap type
+	DetectBalanceSwaps bool
+
+	// PriceOracle, when set, values the processed swaps in SwapInfo.ValueUSD
+	PriceOracle PriceOracle
 }
 
 func NewTransactionParser(tx *rpc.GetTransactionResult) (*Parser, error) {
@@ -38,7 +71,14 @@ func NewTransactionParser(tx *rpc.GetTransactionResult) (*Parser, error) {
 		return nil, fmt.Errorf("failed to get transaction: %w", err)
 	}
 
-	return NewTransactionParserFromTransaction(txInfo, tx.Meta)
+	parser, err := NewTransactionParserFromTransaction(txInfo, tx.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if tx.BlockTime != nil {
+		parser.blockTime = tx.BlockTime.Time()
+	}
+	return parser, nil
 }
 
 func NewTransactionParserFromTransaction(tx *solana.Transaction, txMeta *rpc.TransactionMeta) (*Parser, error) {
@@ -52,10 +92,12 @@ func NewTransactionParserFromTransaction(tx *solana.Transaction, txMeta *rpc.Tra
 	})
 
 	parser := &Parser{
-		txMeta:         txMeta,
-		txInfo:         tx,
-		allAccountKeys: allAccountKeys,
-		Log:            log,
+		txMeta:              txMeta,
+		txInfo:              tx,
+		allAccountKeys:      allAccountKeys,
+		Log:                 log,
+		BotFeeWallets:       defaultBotFeeWallets(),
+		RaydiumCpmmFeeRates: defaultRaydiumCpmmFeeRates(),
 	}
 
 	if err := parser.extractSPLTokenInfo(); err != nil {
@@ -84,14 +126,13 @@ func (p *Parser) ParseTransaction() ([]SwapData, error) {
 		case progID.Equals(JUPITER_PROGRAM_ID):
 			skip = true
 			parsedSwaps = append(parsedSwaps, p.processJupiterSwaps(i)...)
+		case progID.Equals(JUPITER_V4_PROGRAM_ID):
+			skip = true
+			parsedSwaps = append(parsedSwaps, p.processJupiterV4Swaps(i)...)
 		case progID.Equals(MOONSHOT_PROGRAM_ID):
 			skip = true
 			parsedSwaps = append(parsedSwaps, p.processMoonshotSwaps()...)
-		case progID.Equals(BANANA_GUN_PROGRAM_ID) ||
-			progID.Equals(MINTECH_PROGRAM_ID) ||
-			progID.Equals(BLOOM_PROGRAM_ID) ||
-			progID.Equals(NOVA_PROGRAM_ID) ||
-			progID.Equals(MAESTRO_PROGRAM_ID):
+		case isRouterProgram(progID):
 			if innerSwaps := p.processRouterSwaps(i); len(innerSwaps) > 0 {
 				parsedSwaps = append(parsedSwaps, innerSwaps...)
 			}
@@ -106,23 +147,47 @@ func (p *Parser) ParseTransaction() ([]SwapData, error) {
 
 	for i, outerInstruction := range p.txInfo.Message.Instructions {
 		progID := p.allAccountKeys[outerInstruction.ProgramIDIndex]
+		// deposits, withdrawals and pool creations move tokens like swaps do, they are reported by
+		// GetLiquidityEvents and GetPoolsCreated
+		if p.isNonSwapInstruction(outerInstruction) {
+			continue
+		}
 		switch {
-		case progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID) ||
+		case progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processRaydClmmSwaps(i)...)
+		case progID.Equals(RAYDIUM_CPMM_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processRaydCpmmSwaps(i)...)
+		case progID.Equals(RAYDIUM_V4_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processRaydSwaps(i)...)
+			parsedSwaps = append(parsedSwaps, p.processOpenbookV1Trades(i, RAYDIUM_V4_PROGRAM_ID)...)
+		case progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
 			progID.Equals(RAYDIUM_LAUNCHLAB_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("AP51WLiiqTdbZfgyRMs35PsZpdmLuPDdHYmrB23pEtMU")):
+			progID.Equals(RAYDIUM_AP51_PROGRAM_ID):
 			parsedSwaps = append(parsedSwaps, p.processRaydSwaps(i)...)
 		case progID.Equals(ORCA_PROGRAM_ID):
 			parsedSwaps = append(parsedSwaps, p.processOrcaSwaps(i)...)
-		case progID.Equals(METEORA_PROGRAM_ID) || progID.Equals(METEORA_POOLS_PROGRAM_ID) || progID.Equals(METEORA_DLMM_PROGRAM_ID):
+		case progID.Equals(METEORA_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processMeteoraDlmmSwaps(i)...)
+		case progID.Equals(METEORA_POOLS_PROGRAM_ID) || progID.Equals(METEORA_DLMM_PROGRAM_ID):
 			parsedSwaps = append(parsedSwaps, p.processMeteoraSwaps(i)...)
+		case progID.Equals(METEORA_DAMM_V2_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processMeteoraDammV2Swaps(i)...)
+		case progID.Equals(METEORA_DBC_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processMeteoraDbcSwaps(i)...)
 		case progID.Equals(PUMPFUN_AMM_PROGRAM_ID):
 			parsedSwaps = append(parsedSwaps, p.processPumpfunAMMSwaps(i)...)
-		case progID.Equals(PUMP_FUN_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW")):
+		case isStableSwapProgram(progID):
+			parsedSwaps = append(parsedSwaps, p.processStableSwaps(i, progID)...)
+		case isPropAMMProgram(progID):
+			parsedSwaps = append(parsedSwaps, p.processPropAMMSwaps(i, progID)...)
+		case progID.Equals(OPENBOOK_V2_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processOpenbookV2Trades(i)...)
+		case progID.Equals(OPENBOOK_V1_PROGRAM_ID) || progID.Equals(SERUM_V3_PROGRAM_ID):
+			parsedSwaps = append(parsedSwaps, p.processOpenbookV1Trades(i, solana.PublicKey{})...)
+		case progID.Equals(PUMP_FUN_PROGRAM_ID):
 			parsedSwaps = append(parsedSwaps, p.processPumpfunSwaps(i)...)
+		case p.DetectUnknownSwaps && isUnknownSwapCandidate(progID):
+			parsedSwaps = append(parsedSwaps, p.processUnknownSwaps(i, progID)...)
 		}
 	}
 
@@ -133,6 +198,7 @@ type SwapInfo struct {
 	Signers    []solana.PublicKey
 	Signatures []solana.Signature
 	AMMs       []string
+	Router     string
 	Timestamp  time.Time
 
 	TokenInMint     solana.PublicKey
@@ -142,22 +208,58 @@ type SwapInfo struct {
 	TokenOutMint     solana.PublicKey
 	TokenOutAmount   uint64
 	TokenOutDecimals uint8
+
+	Fees    []Fee
+	Cost    TransactionCost
+	JitoTip uint64
+
+	// SOL is the signer's SOL accounting, set when SOL is one of the swapped tokens
+	SOL *SolBalanceChange
+
+	// Reconciliation flags swaps whose amounts don't match the signer's balance changes
+	Reconciliation *Reconciliation
+
+	// Arbitrage is set for cyclic routes, TokenIn and TokenOut are then both its base mint
+	Arbitrage *ArbitrageInfo
+
+	// ValueUSD is the dollar value of the swap, set when a PriceOracle could price one of its sides
+	ValueUSD *big.Rat
 }
 
-func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
-	if len(swapDatas) == 0 {
-		return nil, fmt.Errorf("no swap data provided")
+// getSigner returns the account a trade is attributed to: the fee payer, or the DCA account's owner (the third
+// account) for Jupiter DCA fills, which are signed by a keeper
+func (p *Parser) getSigner() solana.PublicKey {
+	if p.containsDCAProgram() && len(p.allAccountKeys) > 2 {
+		return p.allAccountKeys[2]
 	}
+	return p.allAccountKeys[0]
+}
 
+// getTimestamp returns the block time of the transaction when the parser was created from a fetched
+// transaction that has one, and the current time otherwise
+func (p *Parser) getTimestamp() time.Time {
+	if !p.blockTime.IsZero() {
+		return p.blockTime
+	}
+	return time.Now()
+}
+
+// ProcessSwapData aggregates the decoded swap data into a single swap, transactions without decoded swap data
+// fall back to the signer's balance changes when DetectBalanceSwaps is set
+func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 	swapInfo := &SwapInfo{
 		Signatures: p.txInfo.Signatures,
+		Timestamp:  p.getTimestamp(),
+		Cost:       p.GetTransactionCost(),
+		JitoTip:    p.GetJitoTip(),
 	}
 
-	if p.containsDCAProgram() {
-		swapInfo.Signers = []solana.PublicKey{p.allAccountKeys[2]}
-	} else {
-		swapInfo.Signers = []solana.PublicKey{p.allAccountKeys[0]}
+	swapInfo.Signers = []solana.PublicKey{p.getSigner()}
+
+	if router := p.detectRouter(); router != nil {
+		swapInfo.Router = router.Name
 	}
+	swapInfo.Fees = append(swapInfo.Fees, p.getBotFees()...)
 
 	jupiterSwaps := make([]SwapData, 0)
 	pumpfunSwaps := make([]SwapData, 0)
@@ -166,14 +268,51 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 	for _, swapData := range swapDatas {
 		switch swapData.Type {
 		case JUPITER:
+			switch data := swapData.Data.(type) {
+			case *JupiterRouteInstruction:
+				if data.PlatformFee != nil {
+					swapInfo.Fees = append(swapInfo.Fees, p.jupiterPlatformFee(data.PlatformFee))
+				}
+				continue
+			case *JupiterFeeEvent:
+				swapInfo.Fees = append(swapInfo.Fees, p.jupiterPlatformFee(data))
+				continue
+			}
 			jupiterSwaps = append(jupiterSwaps, swapData)
 		case PUMP_FUN:
 			pumpfunSwaps = append(pumpfunSwaps, swapData)
 		default:
+			if p.isBotFeeTransfer(swapData) {
+				continue
+			}
 			otherSwaps = append(otherSwaps, swapData)
 		}
 	}
 
+	if arbitrage, err := p.ProcessArbitrage(swapDatas); err == nil {
+		swapInfo.Arbitrage = arbitrage
+		swapInfo.TokenInMint = arbitrage.BaseMint
+		swapInfo.TokenInAmount = arbitrage.AmountIn
+		swapInfo.TokenInDecimals = arbitrage.BaseDecimals
+		swapInfo.TokenOutMint = arbitrage.BaseMint
+		swapInfo.TokenOutAmount = arbitrage.AmountOut
+		swapInfo.TokenOutDecimals = arbitrage.BaseDecimals
+		if len(jupiterSwaps) > 0 {
+			swapInfo.AMMs = []string{string(JUPITER)}
+		} else {
+			seenAMMs := make(map[string]bool)
+			for _, hop := range arbitrage.Hops {
+				if !seenAMMs[hop.AMM] {
+					swapInfo.AMMs = append(swapInfo.AMMs, hop.AMM)
+					seenAMMs[hop.AMM] = true
+				}
+			}
+		}
+		p.finalizeSwapInfo(swapInfo)
+		swapInfo.Reconciliation = p.reconcileArbitrage(arbitrage)
+		return swapInfo, nil
+	}
+
 	if len(jupiterSwaps) > 0 {
 		jupiterInfo, err := parseJupiterEvents(jupiterSwaps)
 		if err != nil {
@@ -188,7 +327,7 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 		swapInfo.TokenOutDecimals = jupiterInfo.TokenOutDecimals
 		swapInfo.AMMs = jupiterInfo.AMMs
 
-		return swapInfo, nil
+		return p.finalizeSwapInfo(swapInfo), nil
 	}
 
 	if len(pumpfunSwaps) > 0 {
@@ -211,7 +350,7 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 			}
 			swapInfo.AMMs = append(swapInfo.AMMs, string(pumpfunSwaps[0].Type))
 			swapInfo.Timestamp = time.Unix(int64(data.Timestamp), 0)
-			return swapInfo, nil
+			return p.finalizeSwapInfo(swapInfo), nil
 		default:
 			otherSwaps = append(otherSwaps, pumpfunSwaps...)
 		}
@@ -222,10 +361,11 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 		seenTokens := make(map[string]bool)
 
 		for _, swapData := range otherSwaps {
-			transfer := getTransferFromSwapData(swapData)
-			if transfer != nil && !seenTokens[transfer.mint] {
-				uniqueTokens = append(uniqueTokens, *transfer)
-				seenTokens[transfer.mint] = true
+			for _, transfer := range getTransfersFromSwapData(swapData) {
+				if !seenTokens[transfer.mint] {
+					uniqueTokens = append(uniqueTokens, transfer)
+					seenTokens[transfer.mint] = true
+				}
 			}
 		}
 
@@ -233,25 +373,23 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 			inputTransfer := uniqueTokens[0]
 			outputTransfer := uniqueTokens[len(uniqueTokens)-1]
 
-			seenInputs := make(map[string]bool)
-			seenOutputs := make(map[string]bool)
+			// every leg is decoded from exactly one invocation, only the same decoded entry listed twice is skipped
+			seenData := make(map[interface{}]bool)
 			var totalInputAmount uint64 = 0
 			var totalOutputAmount uint64 = 0
 
 			for _, swapData := range otherSwaps {
-				transfer := getTransferFromSwapData(swapData)
-				if transfer == nil {
+				if seenData[swapData.Data] {
 					continue
 				}
-
-				amountStr := fmt.Sprintf("%d-%s", transfer.amount, transfer.mint)
-				if transfer.mint == inputTransfer.mint && !seenInputs[amountStr] {
-					totalInputAmount += transfer.amount
-					seenInputs[amountStr] = true
-				}
-				if transfer.mint == outputTransfer.mint && !seenOutputs[amountStr] {
-					totalOutputAmount += transfer.amount
-					seenOutputs[amountStr] = true
+				seenData[swapData.Data] = true
+				for _, transfer := range getTransfersFromSwapData(swapData) {
+					if transfer.mint == inputTransfer.mint {
+						totalInputAmount += transfer.amount
+					}
+					if transfer.mint == outputTransfer.mint {
+						totalOutputAmount += transfer.amount
+					}
 				}
 			}
 
@@ -270,31 +408,95 @@ func (p *Parser) ProcessSwapData(swapDatas []SwapData) (*SwapInfo, error) {
 				}
 			}
 
-			swapInfo.Timestamp = time.Now()
-			return swapInfo, nil
+			return p.finalizeSwapInfo(swapInfo), nil
 		}
 	}
 
+	// a single-sided withdrawal looks like a swap of the LP token
+	if len(p.GetLiquidityEvents()) > 0 || len(p.GetPoolsCreated()) > 0 {
+		return nil, fmt.Errorf("no valid swaps found")
+	}
+	if p.DetectBalanceSwaps {
+		if err := p.swapInfoFromBalanceChanges(swapInfo); err == nil {
+			return p.finalizeSwapInfo(swapInfo), nil
+		}
+	}
+	if len(swapDatas) == 0 {
+		return nil, fmt.Errorf("no swap data provided")
+	}
 	return nil, fmt.Errorf("no valid swaps found")
 }
 
-func getTransferFromSwapData(swapData SwapData) *TokenTransfer {
+// getTransfersFromSwapData returns the token legs of a swap data entry, transfers yield a single leg
+// while decoded swap events yield the input leg followed by the output leg
+func getTransfersFromSwapData(swapData SwapData) []TokenTransfer {
 	switch data := swapData.Data.(type) {
 	case *TransferData:
-		return &TokenTransfer{
+		return []TokenTransfer{{
 			mint:     data.Mint,
 			amount:   data.Info.Amount,
 			decimals: data.Decimals,
-		}
+		}}
 	case *TransferCheck:
 		amt, err := strconv.ParseUint(data.Info.TokenAmount.Amount, 10, 64)
 		if err != nil {
 			return nil
 		}
-		return &TokenTransfer{
+		return []TokenTransfer{{
 			mint:     data.Info.Mint,
 			amount:   amt,
 			decimals: data.Info.TokenAmount.Decimals,
+		}}
+	case *RaydiumClmmSwapEventData:
+		if data.ZeroForOne {
+			return []TokenTransfer{
+				{mint: data.Mint0.String(), amount: data.Amount0, decimals: data.Mint0Decimals},
+				{mint: data.Mint1.String(), amount: data.Amount1, decimals: data.Mint1Decimals},
+			}
+		}
+		return []TokenTransfer{
+			{mint: data.Mint1.String(), amount: data.Amount1, decimals: data.Mint1Decimals},
+			{mint: data.Mint0.String(), amount: data.Amount0, decimals: data.Mint0Decimals},
+		}
+	case *UnknownSwapData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.InputAmount, decimals: data.InputDecimals},
+			{mint: data.OutputMint.String(), amount: data.OutputAmount, decimals: data.OutputDecimals},
+		}
+	case *RaydiumCpmmSwapEventData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.InputAmount, decimals: data.InputMintDecimals},
+			{mint: data.OutputMint.String(), amount: data.OutputAmount, decimals: data.OutputMintDecimals},
+		}
+	case *OpenbookTradeData:
+		if !data.ViaAmm.IsZero() || data.BaseAmount == 0 || data.QuoteAmount == 0 {
+			return nil
+		}
+		base := TokenTransfer{mint: data.BaseMint.String(), amount: data.BaseAmount, decimals: data.BaseDecimals}
+		quote := TokenTransfer{mint: data.QuoteMint.String(), amount: data.QuoteAmount, decimals: data.QuoteDecimals}
+		if data.Side == TradeTypeBuy {
+			return []TokenTransfer{quote, base}
+		}
+		return []TokenTransfer{base, quote}
+	case *OrcaTradedEventData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.InputAmount, decimals: data.InputMintDecimals},
+			{mint: data.OutputMint.String(), amount: data.OutputAmount, decimals: data.OutputMintDecimals},
+		}
+	case *MeteoraDlmmSwapEventData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.AmountIn, decimals: data.InputMintDecimals},
+			{mint: data.OutputMint.String(), amount: data.AmountOut, decimals: data.OutputMintDecimals},
+		}
+	case *MeteoraDammV2SwapEventData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.ActualAmountIn, decimals: data.InputMintDecimals},
+			{mint: data.OutputMint.String(), amount: data.SwapResult.OutputAmount, decimals: data.OutputMintDecimals},
+		}
+	case *MeteoraDbcSwapEventData:
+		return []TokenTransfer{
+			{mint: data.InputMint.String(), amount: data.SwapResult.ActualInputAmount, decimals: data.InputMintDecimals},
+			{mint: data.OutputMint.String(), amount: data.SwapResult.OutputAmount, decimals: data.OutputMintDecimals},
 		}
 	}
 	return nil
@@ -303,53 +505,32 @@ func getTransferFromSwapData(swapData SwapData) *TokenTransfer {
 func (p *Parser) processRouterSwaps(instructionIndex int) []SwapData {
 	var swaps []SwapData
 
-	innerInstructions := p.getInnerInstructions(instructionIndex)
-	if len(innerInstructions) == 0 {
-		return swaps
-	}
-
-	processedProtocols := make(map[string]bool)
-
-	for _, inner := range innerInstructions {
-		progID := p.allAccountKeys[inner.ProgramIDIndex]
-
-		switch {
-		case (progID.Equals(RAYDIUM_V4_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CPMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_AMM_PROGRAM_ID) ||
-			progID.Equals(RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID)) && !processedProtocols[PROTOCOL_RAYDIUM]:
-			processedProtocols[PROTOCOL_RAYDIUM] = true
-			if raydSwaps := p.processRaydSwaps(instructionIndex); len(raydSwaps) > 0 {
-				swaps = append(swaps, raydSwaps...)
-			}
-
-		case progID.Equals(ORCA_PROGRAM_ID) && !processedProtocols[PROTOCOL_ORCA]:
-			processedProtocols[PROTOCOL_ORCA] = true
-			if orcaSwaps := p.processOrcaSwaps(instructionIndex); len(orcaSwaps) > 0 {
-				swaps = append(swaps, orcaSwaps...)
-			}
-
-		case (progID.Equals(METEORA_PROGRAM_ID) ||
-			progID.Equals(METEORA_POOLS_PROGRAM_ID) ||
-			progID.Equals(METEORA_DLMM_PROGRAM_ID)) && !processedProtocols[PROTOCOL_METEORA]:
-			processedProtocols[PROTOCOL_METEORA] = true
-			if meteoraSwaps := p.processMeteoraSwaps(instructionIndex); len(meteoraSwaps) > 0 {
-				swaps = append(swaps, meteoraSwaps...)
-			}
-
-		case progID.Equals(PUMPFUN_AMM_PROGRAM_ID) && !processedProtocols[PROTOCOL_PUMPFUN]:
-			processedProtocols[PROTOCOL_PUMPFUN] = true
-			if pumpfunAMMSwaps := p.processPumpfunAMMSwaps(instructionIndex); len(pumpfunAMMSwaps) > 0 {
-				swaps = append(swaps, pumpfunAMMSwaps...)
-			}
-
-		case (progID.Equals(PUMP_FUN_PROGRAM_ID) ||
-			progID.Equals(solana.MustPublicKeyFromBase58("BSfD6SHZigAfDWSjzD5Q41jw8LmKwtmjskPH9XW1mrRW"))) && !processedProtocols[PROTOCOL_PUMPFUN]:
-			processedProtocols[PROTOCOL_PUMPFUN] = true
-			if pumpfunSwaps := p.processPumpfunSwaps(instructionIndex); len(pumpfunSwaps) > 0 {
-				swaps = append(swaps, pumpfunSwaps...)
+	// with stack heights every venue invocation is decoded on its own, keyed by its position in the call tree.
+	// Without them the inner instructions can't be told apart and each decoder runs once over the whole
+	// outer instruction.
+	scoped := p.hasStackHeights(instructionIndex)
+	decoded := make(map[InvocationKey]bool)
+	decodedProtocols := make(map[string]bool)
+
+	for _, node := range p.getInnerNodes(instructionIndex) {
+		protocol := getInvocationProtocol(node.ProgramID)
+		if protocol == "" || p.isNonSwapInstruction(node.Instruction) {
+			continue
+		}
+		if scoped {
+			key := InvocationKey{OuterIndex: node.OuterIndex, InnerIndex: node.InnerIndex}
+			if decoded[key] || p.decodedByAncestor(node, protocol) {
+				continue
 			}
+			decoded[key] = true
+			swaps = append(swaps, p.processInvocation(node)...)
+			continue
+		}
+		if decodedProtocols[protocol] {
+			continue
 		}
+		decodedProtocols[protocol] = true
+		swaps = append(swaps, p.decodeVenue(instructionIndex, node.ProgramID)...)
 	}
 
 	return swaps
@@ -360,6 +541,14 @@ func (p *Parser) getInnerInstructions(index int) []solana.CompiledInstruction {
 		return nil
 	}
 
+	if p.scope != nil && p.scope.OuterIndex == index {
+		result := []solana.CompiledInstruction{p.scope.Instruction}
+		for _, node := range p.scope.descendants() {
+			result = append(result, node.Instruction)
+		}
+		return result
+	}
+
 	for _, inner := range p.txMeta.InnerInstructions {
 		if inner.Index == uint16(index) {
 			result := make([]solana.CompiledInstruction, len(inner.Instructions))
@@ -372,3 +561,17 @@ func (p *Parser) getInnerInstructions(index int) []solana.CompiledInstruction {
 
 	return nil
 }
+
+// findInstruction returns the first instruction of the given outer instruction, the outer instruction itself
+// included, that satisfies match
+func (p *Parser) findInstruction(index int, match func(solana.CompiledInstruction) bool) (solana.CompiledInstruction, bool) {
+	if index < len(p.txInfo.Message.Instructions) && match(p.txInfo.Message.Instructions[index]) {
+		return p.txInfo.Message.Instructions[index], true
+	}
+	for _, inner := range p.getInnerInstructions(index) {
+		if match(inner) {
+			return inner, true
+		}
+	}
+	return solana.CompiledInstruction{}, false
+}
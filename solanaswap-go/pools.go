@@ -0,0 +1,181 @@
+package solanaswapgo
+
+import (
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+const RAYDIUM_V4_INITIALIZE2_INSTRUCTION = 1
+
+var (
+	INITIALIZE_DISCRIMINATOR                     = [8]byte{175, 175, 109, 31, 13, 152, 155, 237}
+	CREATE_POOL_DISCRIMINATOR                    = [8]byte{233, 146, 209, 142, 207, 104, 64, 188}
+	INITIALIZE_POOL_DISCRIMINATOR                = [8]byte{95, 180, 10, 172, 84, 174, 232, 40}
+	INITIALIZE_POOL_V2_DISCRIMINATOR             = [8]byte{207, 45, 87, 242, 27, 63, 204, 67}
+	INITIALIZE_CUSTOMIZABLE_POOL_DISCRIMINATOR   = [8]byte{20, 161, 241, 24, 189, 221, 180, 2}
+	INITIALIZE_PERMISSIONLESS_POOL_DISCRIMINATOR = [8]byte{118, 173, 41, 157, 173, 72, 97, 103}
+	INITIALIZE_LB_PAIR_DISCRIMINATOR             = [8]byte{45, 154, 237, 210, 221, 15, 166, 92}
+	INITIALIZE_LB_PAIR2_DISCRIMINATOR            = [8]byte{73, 59, 36, 120, 237, 83, 108, 198}
+)
+
+// poolCreationInstruction describes a pool creation instruction of a venue by the positions of its accounts
+type poolCreationInstruction struct {
+	Name    string
+	Pool    int
+	MintA   int
+	MintB   int
+	Creator int
+}
+
+// poolCreationInstructions maps the anchor pool creation instructions of the supported venues by discriminator
+var poolCreationInstructions = map[solana.PublicKey]map[[8]byte]poolCreationInstruction{
+	RAYDIUM_CPMM_PROGRAM_ID: {
+		INITIALIZE_DISCRIMINATOR: {Name: "initialize", Pool: 3, MintA: 4, MintB: 5, Creator: 0},
+	},
+	RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID: {
+		CREATE_POOL_DISCRIMINATOR: {Name: "createPool", Pool: 2, MintA: 3, MintB: 4, Creator: 0},
+	},
+	ORCA_PROGRAM_ID: {
+		INITIALIZE_POOL_DISCRIMINATOR:    {Name: "initializePool", Pool: 4, MintA: 1, MintB: 2, Creator: 3},
+		INITIALIZE_POOL_V2_DISCRIMINATOR: {Name: "initializePoolV2", Pool: 6, MintA: 1, MintB: 2, Creator: 5},
+	},
+	METEORA_PROGRAM_ID: {
+		INITIALIZE_LB_PAIR_DISCRIMINATOR:  {Name: "initializeLbPair", Pool: 0, MintA: 2, MintB: 3, Creator: 8},
+		INITIALIZE_LB_PAIR2_DISCRIMINATOR: {Name: "initializeLbPair2", Pool: 0, MintA: 2, MintB: 3, Creator: 8},
+	},
+	METEORA_POOLS_PROGRAM_ID: {
+		INITIALIZE_PERMISSIONLESS_POOL_DISCRIMINATOR: {Name: "initializePermissionlessPool", Pool: 0, MintA: 2, MintB: 3, Creator: 15},
+	},
+	METEORA_DAMM_V2_PROGRAM_ID: {
+		INITIALIZE_POOL_DISCRIMINATOR:              {Name: "initializePool", Pool: 6, MintA: 8, MintB: 9, Creator: 0},
+		INITIALIZE_CUSTOMIZABLE_POOL_DISCRIMINATOR: {Name: "initializeCustomizablePool", Pool: 5, MintA: 7, MintB: 8, Creator: 0},
+	},
+	PUMPFUN_AMM_PROGRAM_ID: {
+		CREATE_POOL_DISCRIMINATOR: {Name: "createPool", Pool: 0, MintA: 3, MintB: 4, Creator: 2},
+	},
+}
+
+// PoolCreated is a pool initialized by the transaction, InitialReserves are the amounts of MintA and MintB
+// deposited by the instruction, zero for venues whose pools start empty
+type PoolCreated struct {
+	Signature       solana.Signature
+	Program         solana.PublicKey
+	Instruction     string
+	Pool            solana.PublicKey
+	MintA           solana.PublicKey
+	MintB           solana.PublicKey
+	InitialReserves [2]uint64
+	Creator         solana.PublicKey
+}
+
+// getPoolCreationInstruction returns the description of a pool creation instruction of a supported venue
+func (p *Parser) getPoolCreationInstruction(instr solana.CompiledInstruction) (poolCreationInstruction, bool) {
+	progID := p.allAccountKeys[instr.ProgramIDIndex]
+	decodedBytes, err := base58.Decode(instr.Data.String())
+	if err != nil || len(decodedBytes) == 0 {
+		return poolCreationInstruction{}, false
+	}
+
+	if progID.Equals(RAYDIUM_V4_PROGRAM_ID) {
+		// accounts: token_program, ata_program, system_program, rent, amm, amm_authority, amm_open_orders, lp_mint,
+		// coin_mint, pc_mint, ..., user_wallet
+		if decodedBytes[0] == RAYDIUM_V4_INITIALIZE2_INSTRUCTION {
+			return poolCreationInstruction{Name: "initialize2", Pool: 4, MintA: 8, MintB: 9, Creator: 17}, true
+		}
+		return poolCreationInstruction{}, false
+	}
+
+	instructions, ok := poolCreationInstructions[progID]
+	if !ok || len(decodedBytes) < 8 {
+		return poolCreationInstruction{}, false
+	}
+	var discriminator [8]byte
+	copy(discriminator[:], decodedBytes[:8])
+	creation, ok := instructions[discriminator]
+	return creation, ok
+}
+
+// GetPoolsCreated returns the pools initialized by the transaction, top-level or inside a launchpad's CPI.
+// Without stack heights only top-level pool creations are recognised.
+func (p *Parser) GetPoolsCreated() []PoolCreated {
+	var pools []PoolCreated
+	for i, root := range p.GetCallTree() {
+		nodes := []*InstructionNode{root}
+		if p.hasStackHeights(i) {
+			nodes = append(nodes, root.descendants()...)
+		}
+		for _, node := range nodes {
+			creation, ok := p.getPoolCreationInstruction(node.Instruction)
+			if !ok {
+				continue
+			}
+			if pool := p.newPoolCreated(node, creation); pool != nil {
+				pools = append(pools, *pool)
+			}
+		}
+	}
+	return pools
+}
+
+func (p *Parser) newPoolCreated(node *InstructionNode, creation poolCreationInstruction) *PoolCreated {
+	accounts := node.Instruction.Accounts
+	for _, i := range []int{creation.Pool, creation.MintA, creation.MintB, creation.Creator} {
+		if i >= len(accounts) || int(accounts[i]) >= len(p.allAccountKeys) {
+			p.Log.Warnf("%s instruction has %d accounts", creation.Name, len(accounts))
+			return nil
+		}
+	}
+
+	pool := &PoolCreated{
+		Signature:   p.txInfo.Signatures[0],
+		Program:     node.ProgramID,
+		Instruction: creation.Name,
+		Pool:        p.allAccountKeys[accounts[creation.Pool]],
+		MintA:       p.allAccountKeys[accounts[creation.MintA]],
+		MintB:       p.allAccountKeys[accounts[creation.MintB]],
+		Creator:     p.allAccountKeys[accounts[creation.Creator]],
+	}
+
+	// the initial deposit is whatever the instruction transferred of the pool's mints
+	for _, child := range node.descendants() {
+		var mint string
+		var amount uint64
+		switch {
+		case p.isTransfer(child.Instruction):
+			transfer := p.processTransfer(child.Instruction)
+			mint, amount = transfer.Mint, transfer.Info.Amount
+		case p.isTransferCheck(child.Instruction):
+			transfer := p.processTransferCheck(child.Instruction)
+			if transfer == nil {
+				continue
+			}
+			parsed, err := strconv.ParseUint(transfer.Info.TokenAmount.Amount, 10, 64)
+			if err != nil {
+				continue
+			}
+			mint, amount = transfer.Info.Mint, parsed
+		default:
+			continue
+		}
+		switch mint {
+		case pool.MintA.String():
+			pool.InitialReserves[0] += amount
+		case pool.MintB.String():
+			pool.InitialReserves[1] += amount
+		}
+	}
+	return pool
+}
+
+func (p *Parser) isPoolCreationInstruction(instr solana.CompiledInstruction) bool {
+	_, ok := p.getPoolCreationInstruction(instr)
+	return ok
+}
+
+// isNonSwapInstruction reports whether the instruction moves tokens into or out of a pool without swapping them,
+// such transfers are left out of the swap output
+func (p *Parser) isNonSwapInstruction(instr solana.CompiledInstruction) bool {
+	return p.isLiquidityInstruction(instr) || p.isPoolCreationInstruction(instr)
+}
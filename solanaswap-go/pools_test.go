@@ -0,0 +1,102 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestGetPoolsCreated(t *testing.T) {
+	launchpad := testKey("launchpad")
+	accounts := func(n int, positions map[int]solana.PublicKey) []solana.PublicKey {
+		keys := make([]solana.PublicKey, n)
+		for i := range keys {
+			keys[i] = testKey(fmt.Sprintf("account-%d", i))
+		}
+		for i, key := range positions {
+			keys[i] = key
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name  string
+		build func(b *testTx, pool testPool)
+		want  func(pool testPool) PoolCreated
+	}{
+		{
+			name: "raydium cpmm with initial deposit",
+			build: func(b *testTx, pool testPool) {
+				b.instruction(RAYDIUM_CPMM_PROGRAM_ID, INITIALIZE_DISCRIMINATOR[:],
+					accounts(6, map[int]solana.PublicKey{0: testTrader, 3: pool.address, 4: pool.mintA, 5: pool.mintB})...)
+				b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 100)
+				b.transfer(0, 2, pool.userB, pool.vaultB, testTrader, 200)
+			},
+			want: func(pool testPool) PoolCreated {
+				return PoolCreated{Program: RAYDIUM_CPMM_PROGRAM_ID, Instruction: "initialize", Pool: pool.address,
+					MintA: pool.mintA, MintB: pool.mintB, InitialReserves: [2]uint64{100, 200}, Creator: testTrader}
+			},
+		},
+		{
+			name: "raydium v4",
+			build: func(b *testTx, pool testPool) {
+				b.instruction(RAYDIUM_V4_PROGRAM_ID, []byte{RAYDIUM_V4_INITIALIZE2_INSTRUCTION, 254},
+					accounts(21, map[int]solana.PublicKey{4: pool.address, 8: pool.mintA, 9: pool.mintB, 17: testTrader})...)
+			},
+			want: func(pool testPool) PoolCreated {
+				return PoolCreated{Program: RAYDIUM_V4_PROGRAM_ID, Instruction: "initialize2", Pool: pool.address,
+					MintA: pool.mintA, MintB: pool.mintB, Creator: testTrader}
+			},
+		},
+		{
+			name: "meteora dlmm from a launchpad",
+			build: func(b *testTx, pool testPool) {
+				b.instruction(launchpad, []byte{1}, testTrader)
+				b.invoke(0, 2, METEORA_PROGRAM_ID, INITIALIZE_LB_PAIR_DISCRIMINATOR[:],
+					accounts(9, map[int]solana.PublicKey{0: pool.address, 2: pool.mintA, 3: pool.mintB, 8: launchpad})...)
+			},
+			want: func(pool testPool) PoolCreated {
+				return PoolCreated{Program: METEORA_PROGRAM_ID, Instruction: "initializeLbPair", Pool: pool.address,
+					MintA: pool.mintA, MintB: pool.mintB, Creator: launchpad}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestTx(testTrader)
+			pool := newTestPool(b, "new-pool", testTrader, solana.SolMint, 9, testMint, 6)
+			tt.build(b, pool)
+
+			parser := b.parser(t)
+			pools := parser.GetPoolsCreated()
+			if len(pools) != 1 {
+				t.Fatalf("got %d pools, want 1", len(pools))
+			}
+			want := tt.want(pool)
+			want.Signature = parser.txInfo.Signatures[0]
+			if pools[0] != want {
+				t.Errorf("got %+v, want %+v", pools[0], want)
+			}
+
+			// the initial deposit is not a swap
+			swapDatas, err := parser.ParseTransaction()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(swapDatas) != 0 {
+				t.Errorf("got %d swap data for a pool creation, want none", len(swapDatas))
+			}
+		})
+	}
+}
+
+func TestGetPoolsCreatedIgnoresOtherInstructions(t *testing.T) {
+	b := newTestTx(testTrader)
+	b.instruction(RAYDIUM_CPMM_PROGRAM_ID, DEPOSIT_DISCRIMINATOR[:], testTrader)
+	// a creation with too few accounts is reported and skipped
+	b.instruction(ORCA_PROGRAM_ID, INITIALIZE_POOL_DISCRIMINATOR[:], testTrader)
+	if pools := b.parser(t).GetPoolsCreated(); len(pools) != 0 {
+		t.Errorf("got %d pools, want none", len(pools))
+	}
+}
@@ -0,0 +1,110 @@
+package solanaswapgo
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// TradeSide tells whether the signer bought or sold the base mint of a trade
+type TradeSide string
+
+const (
+	TradeSideBuy  TradeSide = "buy"
+	TradeSideSell TradeSide = "sell"
+)
+
+// DefaultQuoteMints lists the mints trades are quoted in, by priority: a USDC/SOL swap is quoted in USDC
+var DefaultQuoteMints = []solana.PublicKey{USDC_MINT, USDT_MINT, NATIVE_SOL_MINT_PROGRAM_ID}
+
+// Trade is a swap seen from its base mint. The amounts are raw token amounts and Price is the quote per base in UI
+// units (amounts divided by their decimals).
+type Trade struct {
+	Side TradeSide
+
+	BaseMint     solana.PublicKey
+	BaseAmount   uint64
+	BaseDecimals uint8
+
+	QuoteMint     solana.PublicKey
+	QuoteAmount   uint64
+	QuoteDecimals uint8
+
+	Price *big.Rat
+}
+
+// Price returns the price of base in quote in UI units, whichever of the two the signer spent
+func (s *SwapInfo) Price(base, quote solana.PublicKey) (*big.Rat, error) {
+	switch {
+	case s.TokenInMint.Equals(s.TokenOutMint):
+		return nil, fmt.Errorf("swap from %s to itself has no price", s.TokenInMint)
+	case s.TokenOutMint.Equals(base) && s.TokenInMint.Equals(quote):
+		return uiPrice(s.TokenOutAmount, s.TokenOutDecimals, s.TokenInAmount, s.TokenInDecimals)
+	case s.TokenInMint.Equals(base) && s.TokenOutMint.Equals(quote):
+		return uiPrice(s.TokenInAmount, s.TokenInDecimals, s.TokenOutAmount, s.TokenOutDecimals)
+	}
+	return nil, fmt.Errorf("swap from %s to %s is not between %s and %s", s.TokenInMint, s.TokenOutMint, base, quote)
+}
+
+// PriceFloat returns the price of base in quote in UI units as the nearest float64
+func (s *SwapInfo) PriceFloat(base, quote solana.PublicKey) (float64, error) {
+	price, err := s.Price(base, quote)
+	if err != nil {
+		return 0, err
+	}
+	value, _ := price.Float64()
+	return value, nil
+}
+
+// Trade returns the swap seen from its base mint, quoted in the first of DefaultQuoteMints it involves
+func (s *SwapInfo) Trade() (*Trade, error) {
+	return s.TradeWithQuotes(DefaultQuoteMints)
+}
+
+// TradeWithQuotes returns the swap seen from its base mint, quoted in the first of quoteMints it involves. When it
+// involves none the input mint is the quote, the signer bought the output mint with it.
+func (s *SwapInfo) TradeWithQuotes(quoteMints []solana.PublicKey) (*Trade, error) {
+	if s.TokenInMint.Equals(s.TokenOutMint) {
+		return nil, fmt.Errorf("swap from %s to itself is not a trade", s.TokenInMint)
+	}
+
+	side := TradeSideBuy
+	for _, quote := range quoteMints {
+		if s.TokenInMint.Equals(quote) {
+			break
+		}
+		if s.TokenOutMint.Equals(quote) {
+			side = TradeSideSell
+			break
+		}
+	}
+
+	trade := &Trade{Side: side}
+	if side == TradeSideBuy {
+		trade.BaseMint, trade.BaseAmount, trade.BaseDecimals = s.TokenOutMint, s.TokenOutAmount, s.TokenOutDecimals
+		trade.QuoteMint, trade.QuoteAmount, trade.QuoteDecimals = s.TokenInMint, s.TokenInAmount, s.TokenInDecimals
+	} else {
+		trade.BaseMint, trade.BaseAmount, trade.BaseDecimals = s.TokenInMint, s.TokenInAmount, s.TokenInDecimals
+		trade.QuoteMint, trade.QuoteAmount, trade.QuoteDecimals = s.TokenOutMint, s.TokenOutAmount, s.TokenOutDecimals
+	}
+
+	price, err := uiPrice(trade.BaseAmount, trade.BaseDecimals, trade.QuoteAmount, trade.QuoteDecimals)
+	if err != nil {
+		return nil, err
+	}
+	trade.Price = price
+	return trade, nil
+}
+
+// uiPrice returns quoteAmount / 10^quoteDecimals per baseAmount / 10^baseDecimals
+func uiPrice(baseAmount uint64, baseDecimals uint8, quoteAmount uint64, quoteDecimals uint8) (*big.Rat, error) {
+	if baseAmount == 0 {
+		return nil, fmt.Errorf("no base amount")
+	}
+	numerator := new(big.Int).SetUint64(quoteAmount)
+	numerator.Mul(numerator, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(baseDecimals)), nil))
+	denominator := new(big.Int).SetUint64(baseAmount)
+	denominator.Mul(denominator, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(quoteDecimals)), nil))
+	return new(big.Rat).SetFrac(numerator, denominator), nil
+}
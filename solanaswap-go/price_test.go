@@ -0,0 +1,108 @@
+package solanaswapgo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func mustRat(t *testing.T, value string) *big.Rat {
+	t.Helper()
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		t.Fatalf("invalid rat %q", value)
+	}
+	return rat
+}
+
+func TestSwapInfoTrade(t *testing.T) {
+	other := testKey("other-mint")
+	tests := []struct {
+		name  string
+		swap  SwapInfo
+		side  TradeSide
+		base  solana.PublicKey
+		quote solana.PublicKey
+		price string
+	}{
+		{
+			name: "buy with SOL",
+			swap: SwapInfo{TokenInMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenInAmount: 2_000_000_000, TokenInDecimals: 9,
+				TokenOutMint: testMint, TokenOutAmount: 500_000_000, TokenOutDecimals: 6},
+			side: TradeSideBuy, base: testMint, quote: NATIVE_SOL_MINT_PROGRAM_ID, price: "1/250",
+		},
+		{
+			name: "sell SOL for USDC",
+			swap: SwapInfo{TokenInMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenInAmount: 1_000_000_000, TokenInDecimals: 9,
+				TokenOutMint: USDC_MINT, TokenOutAmount: 150_250_000, TokenOutDecimals: 6},
+			side: TradeSideSell, base: NATIVE_SOL_MINT_PROGRAM_ID, quote: USDC_MINT, price: "150.25",
+		},
+		{
+			// USDC comes before SOL, buying SOL with USDC is quoted in USDC
+			name: "buy SOL with USDC",
+			swap: SwapInfo{TokenInMint: USDC_MINT, TokenInAmount: 300_000_000, TokenInDecimals: 6,
+				TokenOutMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenOutAmount: 2_000_000_000, TokenOutDecimals: 9},
+			side: TradeSideBuy, base: NATIVE_SOL_MINT_PROGRAM_ID, quote: USDC_MINT, price: "150",
+		},
+		{
+			name: "no quote mint",
+			swap: SwapInfo{TokenInMint: other, TokenInAmount: 3, TokenInDecimals: 0,
+				TokenOutMint: testMint, TokenOutAmount: 1_000_000, TokenOutDecimals: 6},
+			side: TradeSideBuy, base: testMint, quote: other, price: "3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade, err := tt.swap.Trade()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trade.Side != tt.side || !trade.BaseMint.Equals(tt.base) || !trade.QuoteMint.Equals(tt.quote) {
+				t.Errorf("trade %s %s for %s, want %s %s for %s", trade.Side, trade.BaseMint, trade.QuoteMint, tt.side, tt.base, tt.quote)
+			}
+			if trade.Price.Cmp(mustRat(t, tt.price)) != 0 {
+				t.Errorf("price %s, want %s", trade.Price.RatString(), tt.price)
+			}
+
+			// Price agrees with the trade whichever side the signer was on
+			price, err := tt.swap.Price(tt.base, tt.quote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if price.Cmp(trade.Price) != 0 {
+				t.Errorf("Price %s, want the trade price %s", price.RatString(), trade.Price.RatString())
+			}
+		})
+	}
+}
+
+func TestSwapInfoPriceErrors(t *testing.T) {
+	swap := SwapInfo{TokenInMint: USDC_MINT, TokenInAmount: 150_000_000, TokenInDecimals: 6,
+		TokenOutMint: NATIVE_SOL_MINT_PROGRAM_ID, TokenOutAmount: 1_000_000_000, TokenOutDecimals: 9}
+
+	price, err := swap.PriceFloat(NATIVE_SOL_MINT_PROGRAM_ID, USDC_MINT)
+	if err != nil || price != 150 {
+		t.Errorf("PriceFloat %v, %v, want 150", price, err)
+	}
+	if _, err := swap.Price(testMint, USDC_MINT); err == nil {
+		t.Error("priced a mint the swap doesn't involve")
+	}
+	// quoted in SOL only, the same swap sells USDC
+	if trade, err := swap.TradeWithQuotes([]solana.PublicKey{NATIVE_SOL_MINT_PROGRAM_ID}); err != nil || trade.Side != TradeSideSell || !trade.BaseMint.Equals(USDC_MINT) {
+		t.Errorf("trade quoted in SOL %+v, %v, want a USDC sell", trade, err)
+	}
+
+	arbitrage := SwapInfo{TokenInMint: USDC_MINT, TokenInAmount: 1, TokenOutMint: USDC_MINT, TokenOutAmount: 2}
+	if _, err := arbitrage.Trade(); err == nil {
+		t.Error("swap to the same mint taken for a trade")
+	}
+	if _, err := arbitrage.Price(USDC_MINT, USDC_MINT); err == nil {
+		t.Error("priced a swap to the same mint")
+	}
+
+	noBase := SwapInfo{TokenInMint: USDC_MINT, TokenInAmount: 1, TokenOutMint: testMint}
+	if _, err := noBase.Trade(); err == nil {
+		t.Error("priced a trade without a base amount")
+	}
+}
@@ -0,0 +1,60 @@
+package solanaswapgo
+
+import "github.com/gagliardetto/solana-go"
+
+// ProgramLabels maps known swap venue program IDs to a human-readable name, it is used to label
+// Jupiter route hops and can be extended by callers
+var ProgramLabels = map[solana.PublicKey]string{
+	RAYDIUM_V4_PROGRAM_ID:                     "Raydium V4",
+	RAYDIUM_AMM_PROGRAM_ID:                    "Raydium Route",
+	RAYDIUM_CPMM_PROGRAM_ID:                   "Raydium CPMM",
+	RAYDIUM_CONCENTRATED_LIQUIDITY_PROGRAM_ID: "Raydium CLMM",
+	RAYDIUM_LAUNCHLAB_PROGRAM_ID:              "Raydium Launchlab",
+	RAYDIUM_AP51_PROGRAM_ID:                   "Raydium AP51",
+	METEORA_PROGRAM_ID:                        "Meteora DLMM",
+	METEORA_POOLS_PROGRAM_ID:                  "Meteora Pools",
+	METEORA_DLMM_PROGRAM_ID:                   "Meteora",
+	METEORA_DAMM_V2_PROGRAM_ID:                "Meteora DAMM v2",
+	METEORA_DBC_PROGRAM_ID:                    "Meteora Dynamic Bonding Curve",
+	MOONSHOT_PROGRAM_ID:                       "Moonshot",
+	ORCA_PROGRAM_ID:                           "Orca Whirlpool",
+	PUMP_FUN_PROGRAM_ID:                       "Pumpfun",
+	PUMPFUN_AMM_PROGRAM_ID:                    "PumpSwap",
+	PHOENIX_PROGRAM_ID:                        "Phoenix",
+	LIFINITY_V2_PROGRAM_ID:                    "Lifinity V2",
+	SABER_STABLE_SWAP_PROGRAM_ID:              "Saber",
+	STABBLE_STABLE_SWAP_PROGRAM_ID:            "Stabble Stable Swap",
+	STABBLE_WEIGHTED_SWAP_PROGRAM_ID:          "Stabble Weighted Swap",
+	SANCTUM_INFINITY_PROGRAM_ID:               "Sanctum Infinity",
+	OBRIC_V2_PROGRAM_ID:                       "Obric V2",
+	SOLFI_PROGRAM_ID:                          "SolFi",
+	HUMIDIFI_PROGRAM_ID:                       "HumidiFi",
+	TESSERA_PROGRAM_ID:                        "Tessera V",
+	ZEROFI_PROGRAM_ID:                         "ZeroFi",
+	GOONFI_PROGRAM_ID:                         "GoonFi",
+	OPENBOOK_V2_PROGRAM_ID:                    "OpenBook V2",
+	OPENBOOK_V1_PROGRAM_ID:                    "OpenBook",
+	SERUM_V3_PROGRAM_ID:                       "Serum V3",
+}
+
+// propAMMPrograms maps the closed-source market makers decoded by processPropAMMSwaps to their swap type
+var propAMMPrograms = map[solana.PublicKey]SwapType{
+	SOLFI_PROGRAM_ID:    SOLFI,
+	HUMIDIFI_PROGRAM_ID: HUMIDIFI,
+	TESSERA_PROGRAM_ID:  TESSERA,
+	ZEROFI_PROGRAM_ID:   ZEROFI,
+	GOONFI_PROGRAM_ID:   GOONFI,
+}
+
+// GetProgramLabel returns the human-readable name of a known program, or its address otherwise
+func GetProgramLabel(programID solana.PublicKey) string {
+	if label, ok := ProgramLabels[programID]; ok {
+		return label
+	}
+	return programID.String()
+}
+
+func isPropAMMProgram(progID solana.PublicKey) bool {
+	_, ok := propAMMPrograms[progID]
+	return ok
+}
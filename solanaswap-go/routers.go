@@ -0,0 +1,165 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// RouterInfo describes a trading bot or aggregator front-end. Routers with their own program are recognised by the
+// program ID and their inner AMM calls are unwrapped like any other router, routers which send their trades straight
+// to the AMMs are only recognised by a transfer to one of their fee wallets
+type RouterInfo struct {
+	Name       string
+	Programs   []solana.PublicKey
+	FeeWallets []solana.PublicKey
+}
+
+// Routers is the catalogue of known trading bots and routers with the fee wallets they are known to collect
+// in. Callers can add or replace FeeWallets, or append entries, before creating parsers.
+var Routers = []*RouterInfo{
+	{Name: "BananaGun", Programs: []solana.PublicKey{BANANA_GUN_PROGRAM_ID}, FeeWallets: []solana.PublicKey{BANANA_GUN_FEE_WALLET}},
+	{Name: "Mintech", Programs: []solana.PublicKey{MINTECH_PROGRAM_ID}},
+	{Name: "Bloom", Programs: []solana.PublicKey{BLOOM_PROGRAM_ID}, FeeWallets: []solana.PublicKey{BLOOM_FEE_WALLET}},
+	{Name: "Nova", Programs: []solana.PublicKey{NOVA_PROGRAM_ID}},
+	{Name: "Maestro", Programs: []solana.PublicKey{MAESTRO_PROGRAM_ID}, FeeWallets: []solana.PublicKey{MAESTRO_FEE_WALLET}},
+	{Name: "Photon", Programs: []solana.PublicKey{PHOTON_PROGRAM_ID}, FeeWallets: []solana.PublicKey{PHOTON_FEE_WALLET}},
+	{Name: "DFlow", Programs: []solana.PublicKey{DFLOW_PROGRAM_ID}},
+	{Name: "GMGN", FeeWallets: []solana.PublicKey{GMGN_FEE_WALLET}},
+	{Name: "Axiom", FeeWallets: []solana.PublicKey{AXIOM_FEE_WALLET}},
+	{Name: "Trojan", FeeWallets: []solana.PublicKey{TROJAN_FEE_WALLET}},
+	{Name: "BonkBot", FeeWallets: []solana.PublicKey{BONKBOT_FEE_WALLET}},
+}
+
+// GetRouter returns the catalogue entry of a router program, or nil if the program is not a known router
+func GetRouter(programID solana.PublicKey) *RouterInfo {
+	for _, router := range Routers {
+		for _, program := range router.Programs {
+			if program.Equals(programID) {
+				return router
+			}
+		}
+	}
+	return nil
+}
+
+func isRouterProgram(progID solana.PublicKey) bool {
+	return GetRouter(progID) != nil
+}
+
+// defaultBotFeeWallets maps the fee wallets of the Routers catalogue to their router name
+func defaultBotFeeWallets() map[solana.PublicKey]string {
+	wallets := make(map[solana.PublicKey]string)
+	for _, router := range Routers {
+		for _, wallet := range router.FeeWallets {
+			wallets[wallet] = router.Name
+		}
+	}
+	return wallets
+}
+
+// detectRouter returns the router that executed the transaction, matching the invoked programs first and
+// the recipients of bot fees second
+func (p *Parser) detectRouter() *RouterInfo {
+	for _, instr := range p.getAllInstructions() {
+		if router := GetRouter(p.allAccountKeys[instr.ProgramIDIndex]); router != nil {
+			return router
+		}
+	}
+
+	for _, fee := range p.getBotFees() {
+		name := p.BotFeeWallets[fee.Recipient]
+		for _, router := range Routers {
+			if router.Name == name {
+				return router
+			}
+		}
+		return &RouterInfo{Name: name, FeeWallets: []solana.PublicKey{fee.Recipient}}
+	}
+	return nil
+}
+
+// botFeeWallet returns the bot fee wallet an account belongs to, the account being either the wallet itself
+// or a token account it owns
+func (p *Parser) botFeeWallet(account solana.PublicKey) (solana.PublicKey, bool) {
+	if _, ok := p.BotFeeWallets[account]; ok {
+		return account, true
+	}
+	if owner, ok := p.tokenAccountOwner(account); ok {
+		if _, ok := p.BotFeeWallets[owner]; ok {
+			return owner, true
+		}
+	}
+	return solana.PublicKey{}, false
+}
+
+// getBotFees returns the SOL and token transfers made to the bot fee wallets anywhere in the transaction,
+// top-level or inside a router's CPI
+func (p *Parser) getBotFees() []Fee {
+	if len(p.BotFeeWallets) == 0 {
+		return nil
+	}
+
+	var fees []Fee
+	for _, instr := range p.getAllInstructions() {
+		switch {
+		case p.isSystemTransfer(instr):
+			wallet, ok := p.botFeeWallet(p.allAccountKeys[instr.Accounts[1]])
+			if !ok {
+				continue
+			}
+			fees = append(fees, Fee{
+				Kind:      FeeKindBot,
+				Mint:      NATIVE_SOL_MINT_PROGRAM_ID,
+				Amount:    binary.LittleEndian.Uint64(instr.Data[4:12]),
+				Recipient: wallet,
+			})
+		case p.isTransfer(instr):
+			wallet, ok := p.botFeeWallet(p.allAccountKeys[instr.Accounts[1]])
+			if !ok {
+				continue
+			}
+			mint, err := solana.PublicKeyFromBase58(p.splTokenInfoMap[p.allAccountKeys[instr.Accounts[1]].String()].Mint)
+			if err != nil {
+				continue
+			}
+			fees = append(fees, Fee{
+				Kind:      FeeKindBot,
+				Mint:      mint,
+				Amount:    binary.LittleEndian.Uint64(instr.Data[1:9]),
+				Recipient: wallet,
+			})
+		case p.isTransferCheck(instr):
+			wallet, ok := p.botFeeWallet(p.allAccountKeys[instr.Accounts[2]])
+			if !ok {
+				continue
+			}
+			fees = append(fees, Fee{
+				Kind:      FeeKindBot,
+				Mint:      p.allAccountKeys[instr.Accounts[1]],
+				Amount:    binary.LittleEndian.Uint64(instr.Data[1:9]),
+				Recipient: wallet,
+			})
+		}
+	}
+	return fees
+}
+
+// isBotFeeTransfer reports whether a decoded token transfer pays a bot fee, so it is left out of the swap legs
+func (p *Parser) isBotFeeTransfer(swapData SwapData) bool {
+	var destination string
+	switch data := swapData.Data.(type) {
+	case *TransferData:
+		destination = data.Info.Destination
+	case *TransferCheck:
+		destination = data.Info.Destination
+	default:
+		return false
+	}
+	account, err := solana.PublicKeyFromBase58(destination)
+	if err != nil {
+		return false
+	}
+	_, ok := p.botFeeWallet(account)
+	return ok
+}
@@ -0,0 +1,106 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func systemTransferData(lamports uint64) []byte {
+	return binary.LittleEndian.AppendUint64(binary.LittleEndian.AppendUint32(nil, SYSTEM_TRANSFER_INSTRUCTION), lamports)
+}
+
+func TestDetectRouter(t *testing.T) {
+	newTx := func(router solana.PublicKey) *testTx {
+		b := newTestTx(testTrader)
+		pool := newTestPool(b, "solfi-pool", testTrader, testUSDC, 6, testMint, 6)
+		height := uint16(2)
+		outer := SOLFI_PROGRAM_ID
+		if !router.IsZero() {
+			b.instruction(router, []byte{1}, testTrader)
+			b.invoke(0, 2, SOLFI_PROGRAM_ID, []byte{7}, pool.address)
+			height = 3
+		} else {
+			b.instruction(outer, []byte{7}, pool.address)
+		}
+		b.transfer(0, height, pool.userA, pool.vaultA, testTrader, 100_000)
+		b.transfer(0, height, pool.vaultB, pool.userB, pool.address, 5_000)
+		return b
+	}
+
+	// the router's program is recognised and the venue it calls is unwrapped
+	_, _, swapInfo := newTx(DFLOW_PROGRAM_ID).parse(t)
+	if swapInfo.Router != "DFlow" {
+		t.Errorf("router %q, want DFlow", swapInfo.Router)
+	}
+	assertSwap(t, swapInfo, testUSDC, 100_000, testMint, 5_000)
+
+	_, _, swapInfo = newTx(solana.PublicKey{}).parse(t)
+	if swapInfo.Router != "" {
+		t.Errorf("router %q for a direct swap, want none", swapInfo.Router)
+	}
+	if router := GetRouter(testKey("unknown-program")); router != nil {
+		t.Errorf("unknown program is router %q", router.Name)
+	}
+}
+
+func TestBotFeeWallets(t *testing.T) {
+	feeWallet := testKey("bot-fee-wallet")
+	newTx := func(wallet solana.PublicKey) *testTx {
+		b := newTestTx(testTrader)
+		pool := newTestPool(b, "solfi-pool", testTrader, testKey("usdc"), 6, testMint, 6)
+		b.instruction(SOLFI_PROGRAM_ID, []byte{7}, pool.address, testTrader, pool.userA, pool.userB, pool.vaultA, pool.vaultB)
+		b.transfer(0, 2, pool.userA, pool.vaultA, testTrader, 100_000)
+		b.transfer(0, 2, pool.vaultB, pool.userB, pool.address, 5_000)
+		b.instruction(solana.SystemProgramID, systemTransferData(1_000_000), testTrader, wallet)
+		return b
+	}
+
+	// a bot without a program is recognised by the fee wallet listed in the catalogue
+	_, _, swapInfo := newTx(TROJAN_FEE_WALLET).parse(t)
+	if swapInfo.Router != "Trojan" || len(swapInfo.Fees) != 1 || !swapInfo.Fees[0].Recipient.Equals(TROJAN_FEE_WALLET) {
+		t.Errorf("router %q fees %+v, want the Trojan fee", swapInfo.Router, swapInfo.Fees)
+	}
+
+	// the shipped wallets of bots with a program are recognised too, and a parser can drop them
+	_, _, swapInfo = newTx(BANANA_GUN_FEE_WALLET).parse(t)
+	if swapInfo.Router != "BananaGun" || len(swapInfo.Fees) != 1 {
+		t.Errorf("router %q fees %+v, want the BananaGun fee", swapInfo.Router, swapInfo.Fees)
+	}
+	overridden := newTx(BANANA_GUN_FEE_WALLET).parser(t)
+	delete(overridden.BotFeeWallets, BANANA_GUN_FEE_WALLET)
+	if fees := overridden.getBotFees(); len(fees) != 0 {
+		t.Errorf("fees %+v after the wallet was removed, want none", fees)
+	}
+
+	// a wallet missing from the catalogue is not recognised until the caller lists it
+	_, _, swapInfo = newTx(feeWallet).parse(t)
+	if swapInfo.Router != "" || len(swapInfo.Fees) != 0 {
+		t.Errorf("router %q fees %+v for an unlisted wallet, want none", swapInfo.Router, swapInfo.Fees)
+	}
+
+	parser := newTx(feeWallet).parser(t)
+	parser.BotFeeWallets[feeWallet] = "BananaGun"
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapInfo, err = parser.ProcessSwapData(swapDatas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swapInfo.Router != "BananaGun" {
+		t.Errorf("router %q, want BananaGun", swapInfo.Router)
+	}
+	want := Fee{Kind: FeeKindBot, Mint: NATIVE_SOL_MINT_PROGRAM_ID, Amount: 1_000_000, Recipient: feeWallet}
+	if len(swapInfo.Fees) != 1 || swapInfo.Fees[0] != want {
+		t.Errorf("fees %+v, want [%+v]", swapInfo.Fees, want)
+	}
+	assertSwap(t, swapInfo, testKey("usdc"), 100_000, testMint, 5_000)
+
+	// the wallets are per parser
+	if other := newTx(feeWallet).parser(t); other.BotFeeWallets[feeWallet] != "" {
+		t.Errorf("new parser has the fee wallet of another parser")
+	}
+}
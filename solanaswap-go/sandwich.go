@@ -0,0 +1,135 @@
+package solanaswapgo
+
+import (
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// BlockSwap is a parsed swap with the position of its transaction in the block and the pool it traded against
+type BlockSwap struct {
+	Index int
+	Pool  solana.PublicKey
+	Swap  *SwapInfo
+}
+
+// Sandwich is a victim swap wrapped by an attacker's front-run and back-run on the same pool. Profit is the
+// attacker's gain in ProfitMint, the front-run input mint, and VictimLoss the output the victim missed in
+// LossMint, estimated as the victim's input traded at the front-run's average price.
+type Sandwich struct {
+	Pool     solana.PublicKey
+	Attacker solana.PublicKey
+	FrontRun BlockSwap
+	Victim   BlockSwap
+	BackRun  BlockSwap
+
+	ProfitMint solana.PublicKey
+	Profit     int64
+	LossMint   solana.PublicKey
+	VictimLoss uint64
+}
+
+// SandwichDetector finds sandwiches in the swaps of a block
+type SandwichDetector struct {
+	// MaxGap is the number of transactions allowed between two legs of a sandwich, 0 requires adjacent ones
+	MaxGap int
+
+	// LinkedAccounts maps accounts to the attacker they are operated by, swaps signed by linked accounts are
+	// treated as signed by the same attacker
+	LinkedAccounts map[solana.PublicKey]solana.PublicKey
+}
+
+func NewSandwichDetector() *SandwichDetector {
+	return &SandwichDetector{
+		LinkedAccounts: make(map[solana.PublicKey]solana.PublicKey),
+	}
+}
+
+// Detect returns the sandwiches of the swaps, which must be ordered by their position in the block. A swap is
+// used in at most one sandwich.
+func (d *SandwichDetector) Detect(swaps []BlockSwap) []Sandwich {
+	var sandwiches []Sandwich
+	used := make(map[int]bool)
+
+	for i, front := range swaps {
+		if used[i] || !isSandwichLeg(front) {
+			continue
+		}
+		attacker := d.attacker(front.Swap)
+
+		for j := i + 1; j < len(swaps) && swaps[j].Index-front.Index-1 <= d.MaxGap; j++ {
+			victim := swaps[j]
+			if used[j] || !isSandwichLeg(victim) || !victim.Pool.Equals(front.Pool) || d.attacker(victim.Swap).Equals(attacker) {
+				continue
+			}
+			if !sameDirection(front.Swap, victim.Swap) {
+				continue
+			}
+
+			k := d.findBackRun(swaps, j, front, attacker, used)
+			if k < 0 {
+				continue
+			}
+			used[i], used[j], used[k] = true, true, true
+			sandwiches = append(sandwiches, newSandwich(attacker, front, victim, swaps[k]))
+			break
+		}
+	}
+	return sandwiches
+}
+
+// findBackRun returns the index of the attacker's swap reversing the front-run after the victim, or -1
+func (d *SandwichDetector) findBackRun(swaps []BlockSwap, victimIndex int, front BlockSwap, attacker solana.PublicKey, used map[int]bool) int {
+	victim := swaps[victimIndex]
+	for k := victimIndex + 1; k < len(swaps) && swaps[k].Index-victim.Index-1 <= d.MaxGap; k++ {
+		back := swaps[k]
+		if used[k] || !isSandwichLeg(back) || !back.Pool.Equals(front.Pool) || !d.attacker(back.Swap).Equals(attacker) {
+			continue
+		}
+		if back.Swap.TokenInMint.Equals(front.Swap.TokenOutMint) && back.Swap.TokenOutMint.Equals(front.Swap.TokenInMint) {
+			return k
+		}
+	}
+	return -1
+}
+
+// attacker returns the account a swap is attributed to, its signer or the attacker it is linked to
+func (d *SandwichDetector) attacker(swap *SwapInfo) solana.PublicKey {
+	signer := swap.Signers[0]
+	if linked, ok := d.LinkedAccounts[signer]; ok {
+		return linked
+	}
+	return signer
+}
+
+func isSandwichLeg(swap BlockSwap) bool {
+	return swap.Swap != nil && len(swap.Swap.Signers) > 0 && !swap.Pool.IsZero() && swap.Swap.Arbitrage == nil &&
+		!swap.Swap.TokenInMint.Equals(swap.Swap.TokenOutMint)
+}
+
+func sameDirection(a, b *SwapInfo) bool {
+	return a.TokenInMint.Equals(b.TokenInMint) && a.TokenOutMint.Equals(b.TokenOutMint)
+}
+
+func newSandwich(attacker solana.PublicKey, front, victim, back BlockSwap) Sandwich {
+	sandwich := Sandwich{
+		Pool:       front.Pool,
+		Attacker:   attacker,
+		FrontRun:   front,
+		Victim:     victim,
+		BackRun:    back,
+		ProfitMint: front.Swap.TokenInMint,
+		Profit:     int64(back.Swap.TokenOutAmount) - int64(front.Swap.TokenInAmount),
+		LossMint:   victim.Swap.TokenOutMint,
+	}
+
+	if front.Swap.TokenInAmount > 0 {
+		expected := new(big.Int).SetUint64(victim.Swap.TokenInAmount)
+		expected.Mul(expected, new(big.Int).SetUint64(front.Swap.TokenOutAmount))
+		expected.Quo(expected, new(big.Int).SetUint64(front.Swap.TokenInAmount))
+		if expected.IsUint64() && expected.Uint64() > victim.Swap.TokenOutAmount {
+			sandwich.VictimLoss = expected.Uint64() - victim.Swap.TokenOutAmount
+		}
+	}
+	return sandwich
+}
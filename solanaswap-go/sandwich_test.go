@@ -0,0 +1,62 @@
+package solanaswapgo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+type sandwichFixture struct {
+	MaxGap         int                                   `json:"maxGap"`
+	LinkedAccounts map[solana.PublicKey]solana.PublicKey `json:"linkedAccounts"`
+	Swaps          []BlockSwap                           `json:"swaps"`
+	Sandwiches     []Sandwich                            `json:"sandwiches"`
+}
+
+func TestSandwichDetectorFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "sandwich", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no sandwich fixtures found")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fixture sandwichFixture
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				t.Fatalf("error decoding fixture: %s", err)
+			}
+
+			detector := NewSandwichDetector()
+			detector.MaxGap = fixture.MaxGap
+			for account, attacker := range fixture.LinkedAccounts {
+				detector.LinkedAccounts[account] = attacker
+			}
+			got := detector.Detect(fixture.Swaps)
+
+			if len(got) != len(fixture.Sandwiches) {
+				t.Fatalf("got %d sandwiches, want %d", len(got), len(fixture.Sandwiches))
+			}
+			gotJSON, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantJSON, err := json.Marshal(fixture.Sandwiches)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("sandwiches mismatch\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	SYSTEM_CREATE_ACCOUNT_INSTRUCTION           = 0
+	SYSTEM_CREATE_ACCOUNT_WITH_SEED_INSTRUCTION = 3
+
+	TOKEN_INITIALIZE_ACCOUNT_INSTRUCTION  = 1
+	TOKEN_CLOSE_ACCOUNT_INSTRUCTION       = 9
+	TOKEN_INITIALIZE_ACCOUNT2_INSTRUCTION = 16
+	TOKEN_SYNC_NATIVE_INSTRUCTION         = 17
+	TOKEN_INITIALIZE_ACCOUNT3_INSTRUCTION = 18
+
+	// ACCOUNT_STORAGE_OVERHEAD and RENT_EXEMPT_LAMPORTS_PER_BYTE give the rent-exempt minimum of an account
+	ACCOUNT_STORAGE_OVERHEAD      = 128
+	RENT_EXEMPT_LAMPORTS_PER_BYTE = 3480 * 2
+
+	// TOKEN_ACCOUNT_RENT is the rent-exempt minimum of a 165 byte SPL token account
+	TOKEN_ACCOUNT_RENT = (165 + ACCOUNT_STORAGE_OVERHEAD) * RENT_EXEMPT_LAMPORTS_PER_BYTE
+)
+
+// SolBalanceChange is an owner's SOL flow through a transaction. NetLamports is the change of the owner's system
+// account and NetWrappedLamports the change of the wSOL token accounts it still holds after the transaction,
+// SwapLamports is their sum with the transaction fee, Jito tip, SOL bot fees and token account rent taken out,
+// i.e. the SOL the owner actually traded (negative when SOL was spent).
+type SolBalanceChange struct {
+	Owner              solana.PublicKey
+	NetLamports        int64
+	NetWrappedLamports int64
+	SwapLamports       int64
+
+	RentPaid     uint64
+	RentRefunded uint64
+
+	// Native is set when SOL moved straight out of or into the owner's system account, Wrapped when it went
+	// through a wSOL token account of the owner
+	Native          bool
+	Wrapped         bool
+	WrappedAccounts []solana.PublicKey
+}
+
+// getInitializedTokenAccountMints returns the mint of every token account initialized by the transaction,
+// directly or through the associated token account program
+func (p *Parser) getInitializedTokenAccountMints() map[string]solana.PublicKey {
+	mints := make(map[string]solana.PublicKey)
+	for _, instr := range p.getAllInstructions() {
+		if !p.accountIndexesValid(instr) {
+			continue
+		}
+		progID := p.allAccountKeys[instr.ProgramIDIndex]
+		switch {
+		case progID.Equals(solana.TokenProgramID) || progID.Equals(solana.Token2022ProgramID):
+			if len(instr.Data) == 0 || len(instr.Accounts) < 2 {
+				continue
+			}
+			switch instr.Data[0] {
+			case TOKEN_INITIALIZE_ACCOUNT_INSTRUCTION, TOKEN_INITIALIZE_ACCOUNT2_INSTRUCTION, TOKEN_INITIALIZE_ACCOUNT3_INSTRUCTION:
+				mints[p.allAccountKeys[instr.Accounts[0]].String()] = p.allAccountKeys[instr.Accounts[1]]
+			}
+		case progID.Equals(solana.SPLAssociatedTokenAccountProgramID):
+			// create and createIdempotent accounts: funder, associated_account, wallet, mint, ...
+			if len(instr.Data) > 0 && instr.Data[0] > 1 || len(instr.Accounts) < 4 {
+				continue
+			}
+			mints[p.allAccountKeys[instr.Accounts[1]].String()] = p.allAccountKeys[instr.Accounts[3]]
+		}
+	}
+	return mints
+}
+
+func (p *Parser) accountIndexesValid(instr solana.CompiledInstruction) bool {
+	if int(instr.ProgramIDIndex) >= len(p.allAccountKeys) {
+		return false
+	}
+	for _, index := range instr.Accounts {
+		if int(index) >= len(p.allAccountKeys) {
+			return false
+		}
+	}
+	return true
+}
+
+// GetSolBalanceChange follows the SOL of an owner through the transaction: its system account balance, its wSOL
+// token accounts, the token accounts it funded and the rent refunded when they were closed
+func (p *Parser) GetSolBalanceChange(owner solana.PublicKey) SolBalanceChange {
+	change := SolBalanceChange{Owner: owner}
+	if p.txMeta == nil {
+		return change
+	}
+
+	ownerIndex := -1
+	for i, key := range p.allAccountKeys {
+		if key.Equals(owner) {
+			ownerIndex = i
+			break
+		}
+	}
+	if ownerIndex >= 0 && ownerIndex < len(p.txMeta.PreBalances) && ownerIndex < len(p.txMeta.PostBalances) {
+		change.NetLamports = int64(p.txMeta.PostBalances[ownerIndex]) - int64(p.txMeta.PreBalances[ownerIndex])
+	}
+
+	// wSOL token accounts of the owner, closed accounts only appear in the pre balances
+	wrappedBalances := make(map[uint16]int64)
+	for _, balance := range p.txMeta.PreTokenBalances {
+		if balance.Owner != nil && balance.Owner.Equals(owner) && balance.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+			amount, _ := strconv.ParseInt(balance.UiTokenAmount.Amount, 10, 64)
+			wrappedBalances[balance.AccountIndex] -= amount
+		}
+	}
+	for _, balance := range p.txMeta.PostTokenBalances {
+		if balance.Owner != nil && balance.Owner.Equals(owner) && balance.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+			amount, _ := strconv.ParseInt(balance.UiTokenAmount.Amount, 10, 64)
+			wrappedBalances[balance.AccountIndex] += amount
+		}
+	}
+	wrappedAccounts := make(map[solana.PublicKey]bool)
+	for index, delta := range wrappedBalances {
+		change.NetWrappedLamports += delta
+		if int(index) < len(p.allAccountKeys) {
+			wrappedAccounts[p.allAccountKeys[index]] = true
+		}
+	}
+
+	initializedMints := p.getInitializedTokenAccountMints()
+	isWrappedAccount := func(account solana.PublicKey) bool {
+		if wrappedAccounts[account] {
+			return true
+		}
+		mint, ok := initializedMints[account.String()]
+		return ok && mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID)
+	}
+
+	// rent of the token accounts funded by the owner, the lamports above it in a wSOL account are the wrapped SOL
+	createdRent := make(map[solana.PublicKey]uint64)
+	for _, instr := range p.getAllInstructions() {
+		if !p.accountIndexesValid(instr) {
+			continue
+		}
+		progID := p.allAccountKeys[instr.ProgramIDIndex]
+		switch {
+		case progID.Equals(solana.SystemProgramID):
+			if len(instr.Data) < 4 || len(instr.Accounts) < 2 || !p.allAccountKeys[instr.Accounts[0]].Equals(owner) {
+				continue
+			}
+			created := p.allAccountKeys[instr.Accounts[1]]
+			switch binary.LittleEndian.Uint32(instr.Data[:4]) {
+			case SYSTEM_CREATE_ACCOUNT_INSTRUCTION, SYSTEM_CREATE_ACCOUNT_WITH_SEED_INSTRUCTION:
+				lamports, space, programOwner, ok := decodeCreateAccount(instr.Data)
+				if !ok || !(programOwner.Equals(solana.TokenProgramID) || programOwner.Equals(solana.Token2022ProgramID)) {
+					continue
+				}
+				rent := min((space+ACCOUNT_STORAGE_OVERHEAD)*RENT_EXEMPT_LAMPORTS_PER_BYTE, lamports)
+				createdRent[created] = rent
+				change.RentPaid += rent
+				if lamports > rent {
+					change.Wrapped = true
+					wrappedAccounts[created] = true
+				}
+			case SYSTEM_TRANSFER_INSTRUCTION:
+				if isWrappedAccount(created) {
+					change.Wrapped = true
+					wrappedAccounts[created] = true
+				} else if !isJitoTipAccount(created) {
+					if _, isFee := p.botFeeWallet(created); !isFee {
+						change.Native = true
+					}
+				}
+			}
+
+		case progID.Equals(solana.TokenProgramID) || progID.Equals(solana.Token2022ProgramID):
+			if len(instr.Data) == 0 || len(instr.Accounts) < 1 {
+				continue
+			}
+			account := p.allAccountKeys[instr.Accounts[0]]
+			switch instr.Data[0] {
+			case TOKEN_SYNC_NATIVE_INSTRUCTION:
+				if isWrappedAccount(account) || createdRent[account] > 0 {
+					change.Wrapped = true
+					wrappedAccounts[account] = true
+				}
+			case TOKEN_CLOSE_ACCOUNT_INSTRUCTION:
+				if len(instr.Accounts) < 2 || !p.allAccountKeys[instr.Accounts[1]].Equals(owner) {
+					continue
+				}
+				change.RentRefunded += p.tokenAccountRent(account, createdRent)
+				if isWrappedAccount(account) {
+					change.Wrapped = true
+					wrappedAccounts[account] = true
+				}
+			}
+		}
+	}
+
+	for account := range wrappedAccounts {
+		change.WrappedAccounts = append(change.WrappedAccounts, account)
+	}
+
+	change.SwapLamports = change.NetLamports + change.NetWrappedLamports
+	change.SwapLamports -= int64(change.RentRefunded) - int64(change.RentPaid)
+	if ownerIndex == 0 {
+		change.SwapLamports += int64(p.txMeta.Fee)
+	}
+	change.SwapLamports += int64(p.getOwnerSolOutflows(owner))
+
+	// SOL paid out by programs such as the Pumpfun bonding curve is a direct lamport credit without an instruction
+	if !change.Wrapped && change.SwapLamports != 0 {
+		change.Native = true
+	}
+
+	return change
+}
+
+// getOwnerSolOutflows returns the lamports the owner paid in Jito tips and SOL bot fees
+func (p *Parser) getOwnerSolOutflows(owner solana.PublicKey) uint64 {
+	var total uint64
+	for _, instr := range p.getAllInstructions() {
+		if !p.isSystemTransfer(instr) || !p.allAccountKeys[instr.Accounts[0]].Equals(owner) {
+			continue
+		}
+		recipient := p.allAccountKeys[instr.Accounts[1]]
+		if _, isFee := p.botFeeWallet(recipient); isFee || isJitoTipAccount(recipient) {
+			total += binary.LittleEndian.Uint64(instr.Data[4:12])
+		}
+	}
+	return total
+}
+
+// tokenAccountRent returns the rent held by a token account, taken from its creation when the transaction
+// funded it and from its balance otherwise
+func (p *Parser) tokenAccountRent(account solana.PublicKey, createdRent map[solana.PublicKey]uint64) uint64 {
+	if rent, ok := createdRent[account]; ok {
+		return rent
+	}
+	for i, key := range p.allAccountKeys {
+		if !key.Equals(account) || i >= len(p.txMeta.PreBalances) {
+			continue
+		}
+		lamports := p.txMeta.PreBalances[i]
+		for _, balance := range p.txMeta.PreTokenBalances {
+			if int(balance.AccountIndex) == i && balance.Mint.Equals(NATIVE_SOL_MINT_PROGRAM_ID) {
+				amount, _ := strconv.ParseUint(balance.UiTokenAmount.Amount, 10, 64)
+				lamports -= min(amount, lamports)
+			}
+		}
+		if lamports > 0 {
+			return lamports
+		}
+	}
+	return TOKEN_ACCOUNT_RENT
+}
+
+// decodeCreateAccount returns the lamports, space and owner program of a System Program createAccount or
+// createAccountWithSeed instruction
+func decodeCreateAccount(data []byte) (lamports, space uint64, owner solana.PublicKey, ok bool) {
+	if len(data) < 4 {
+		return 0, 0, solana.PublicKey{}, false
+	}
+	args := data[4:]
+	if binary.LittleEndian.Uint32(data[:4]) == SYSTEM_CREATE_ACCOUNT_WITH_SEED_INSTRUCTION {
+		// base pubkey, seed string (u64 length prefix), then the createAccount arguments
+		if len(args) < 40 {
+			return 0, 0, solana.PublicKey{}, false
+		}
+		seedLength := binary.LittleEndian.Uint64(args[32:40])
+		if uint64(len(args)-40) < seedLength {
+			return 0, 0, solana.PublicKey{}, false
+		}
+		args = args[40+seedLength:]
+	}
+	if len(args) < 48 {
+		return 0, 0, solana.PublicKey{}, false
+	}
+	return binary.LittleEndian.Uint64(args[0:8]),
+		binary.LittleEndian.Uint64(args[8:16]),
+		solana.PublicKeyFromBytes(args[16:48]),
+		true
+}
@@ -0,0 +1,73 @@
+package solanaswapgo
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func createAccountData(lamports, space uint64, owner solana.PublicKey) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, SYSTEM_CREATE_ACCOUNT_INSTRUCTION)
+	data = binary.LittleEndian.AppendUint64(data, lamports)
+	data = binary.LittleEndian.AppendUint64(data, space)
+	return append(data, owner[:]...)
+}
+
+func TestSolBalanceChangeWrapped(t *testing.T) {
+	wrapped := testKey("trader/wsol")
+	pool := testKey("pool")
+	b := newTestTx(testTrader)
+	// the trader wraps 1 SOL in a fresh account, swaps it and closes the account for its rent
+	b.instruction(solana.SystemProgramID, createAccountData(TOKEN_ACCOUNT_RENT+1_000_000_000, 165, solana.TokenProgramID), testTrader, wrapped)
+	b.instruction(solana.TokenProgramID, append([]byte{TOKEN_INITIALIZE_ACCOUNT3_INSTRUCTION}, testTrader[:]...), wrapped, NATIVE_SOL_MINT_PROGRAM_ID)
+	b.instruction(solana.TokenProgramID, []byte{TOKEN_SYNC_NATIVE_INSTRUCTION}, wrapped)
+	b.instruction(SOLFI_PROGRAM_ID, []byte{7}, pool, testTrader, wrapped)
+	b.transfer(3, 2, wrapped, testKey("pool/vault"), testTrader, 1_000_000_000)
+	b.instruction(solana.TokenProgramID, []byte{TOKEN_CLOSE_ACCOUNT_INSTRUCTION}, wrapped, testTrader, testTrader)
+	b.instruction(solana.SystemProgramID, systemTransferData(1_000), testTrader, JitoTipAccounts[0])
+	b.meta.Fee = 5_000
+	b.setLamports(testTrader, 10_000_000_000, 10_000_000_000-1_000_000_000-5_000-1_000)
+
+	change := b.parser(t).GetSolBalanceChange(testTrader)
+	if change.NetLamports != -1_000_006_000 || change.NetWrappedLamports != 0 {
+		t.Errorf("net %d wrapped %d, want -1000006000 0", change.NetLamports, change.NetWrappedLamports)
+	}
+	if change.RentPaid != TOKEN_ACCOUNT_RENT || change.RentRefunded != TOKEN_ACCOUNT_RENT {
+		t.Errorf("rent paid %d refunded %d, want %d both", change.RentPaid, change.RentRefunded, TOKEN_ACCOUNT_RENT)
+	}
+	// the fee and the tip are not part of the trade
+	if change.SwapLamports != -1_000_000_000 {
+		t.Errorf("swap lamports %d, want -1000000000", change.SwapLamports)
+	}
+	if !change.Wrapped || change.Native {
+		t.Errorf("wrapped %t native %t, want wrapped only", change.Wrapped, change.Native)
+	}
+	if len(change.WrappedAccounts) != 1 || !change.WrappedAccounts[0].Equals(wrapped) {
+		t.Errorf("wrapped accounts %v, want [%s]", change.WrappedAccounts, wrapped)
+	}
+}
+
+func TestSolBalanceChangeNative(t *testing.T) {
+	b := newTestTx(testTrader)
+	// a bonding curve pays SOL out by crediting the trader's account directly
+	b.instruction(PUMP_FUN_PROGRAM_ID, []byte{1}, testTrader)
+	b.meta.Fee = 5_000
+	b.setLamports(testTrader, 1_000_000_000, 1_500_000_000-5_000)
+
+	change := b.parser(t).GetSolBalanceChange(testTrader)
+	if change.SwapLamports != 500_000_000 || !change.Native || change.Wrapped {
+		t.Errorf("swap lamports %d native %t wrapped %t, want 500000000 native", change.SwapLamports, change.Native, change.Wrapped)
+	}
+}
+
+func TestSolBalanceChangeInvalidAccountIndex(t *testing.T) {
+	b := newTestTx(testTrader)
+	b.instruction(solana.MemoProgramID, []byte("gm"), testTrader)
+	b.meta.PostTokenBalances = append(b.meta.PostTokenBalances, testTokenBalance(99, testTrader, NATIVE_SOL_MINT_PROGRAM_ID, 9, 5))
+
+	change := b.parser(t).GetSolBalanceChange(testTrader)
+	if len(change.WrappedAccounts) != 0 {
+		t.Errorf("wrapped accounts %v, want none", change.WrappedAccounts)
+	}
+}
@@ -0,0 +1,322 @@
+package solanaswapgo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// The decoder tests run on synthetic transactions built with testTx: the instructions, events and token balances
+// follow the programs' account and data layouts, and the log messages are generated from the call tree the way the
+// runtime writes them.
+
+// testKey returns a deterministic key for a fixture account
+func testKey(name string) solana.PublicKey {
+	hash := sha256.Sum256([]byte(name))
+	return solana.PublicKeyFromBytes(hash[:])
+}
+
+// testInstruction is an instruction of a synthetic transaction with the "Program data" logs its program emits
+type testInstruction struct {
+	program  solana.PublicKey
+	accounts []solana.PublicKey
+	data     []byte
+	height   uint16
+	logs     [][]byte
+}
+
+// emit adds a "Program data" log to the instruction's frame
+func (i *testInstruction) emit(data []byte) *testInstruction {
+	i.logs = append(i.logs, data)
+	return i
+}
+
+type testTx struct {
+	keys     []solana.PublicKey
+	outer    []*testInstruction
+	inner    map[int][]*testInstruction
+	lamports map[solana.PublicKey][2]uint64
+
+	// noStackHeights leaves the stack heights out of the inner instructions, as in transactions from before
+	// they were recorded
+	noStackHeights bool
+
+	meta rpc.TransactionMeta
+}
+
+func newTestTx(signer solana.PublicKey) *testTx {
+	b := &testTx{
+		inner:    make(map[int][]*testInstruction),
+		lamports: make(map[solana.PublicKey][2]uint64),
+	}
+	b.index(signer)
+	return b
+}
+
+func (b *testTx) index(key solana.PublicKey) uint16 {
+	for i, k := range b.keys {
+		if k.Equals(key) {
+			return uint16(i)
+		}
+	}
+	b.keys = append(b.keys, key)
+	return uint16(len(b.keys) - 1)
+}
+
+// instruction adds an outer instruction
+func (b *testTx) instruction(program solana.PublicKey, data []byte, accounts ...solana.PublicKey) *testInstruction {
+	instr := &testInstruction{program: program, accounts: accounts, data: data, height: 1}
+	b.outer = append(b.outer, instr)
+	return instr
+}
+
+// invoke adds an inner instruction to an outer instruction at the given stack height, the instruction invoking it
+// is the last one added one level above
+func (b *testTx) invoke(outer int, height uint16, program solana.PublicKey, data []byte, accounts ...solana.PublicKey) *testInstruction {
+	instr := &testInstruction{program: program, accounts: accounts, data: data, height: height}
+	b.inner[outer] = append(b.inner[outer], instr)
+	return instr
+}
+
+// transfer adds an SPL Token transfer to an outer instruction
+func (b *testTx) transfer(outer int, height uint16, source, destination, authority solana.PublicKey, amount uint64) *testInstruction {
+	return b.invoke(outer, height, solana.TokenProgramID, tokenTransferData(amount), source, destination, authority)
+}
+
+// tokenAccount records the balances of a token account before and after the transaction
+func (b *testTx) tokenAccount(account, owner, mint solana.PublicKey, decimals uint8, pre, post uint64) {
+	b.meta.PreTokenBalances = append(b.meta.PreTokenBalances, testTokenBalance(b.index(account), owner, mint, decimals, pre))
+	b.meta.PostTokenBalances = append(b.meta.PostTokenBalances, testTokenBalance(b.index(account), owner, mint, decimals, post))
+}
+
+// setLamports records the SOL balances of an account before and after the transaction
+func (b *testTx) setLamports(account solana.PublicKey, pre, post uint64) {
+	b.index(account)
+	b.lamports[account] = [2]uint64{pre, post}
+}
+
+func testTokenBalance(index uint16, owner, mint solana.PublicKey, decimals uint8, amount uint64) rpc.TokenBalance {
+	return rpc.TokenBalance{
+		AccountIndex: index,
+		Owner:        &owner,
+		Mint:         mint,
+		UiTokenAmount: &rpc.UiTokenAmount{
+			Amount:   strconv.FormatUint(amount, 10),
+			Decimals: decimals,
+		},
+	}
+}
+
+func (b *testTx) compile(instr *testInstruction) (uint16, []uint16) {
+	programIndex := b.index(instr.program)
+	accounts := make([]uint16, len(instr.accounts))
+	for i, account := range instr.accounts {
+		accounts[i] = b.index(account)
+	}
+	return programIndex, accounts
+}
+
+// build returns the transaction and its meta, with the log messages of the call tree
+func (b *testTx) build() (*solana.Transaction, *rpc.TransactionMeta) {
+	tx := &solana.Transaction{Signatures: []solana.Signature{{1}}}
+	meta := b.meta
+
+	var logs []string
+	for i, outer := range b.outer {
+		programIndex, accounts := b.compile(outer)
+		tx.Message.Instructions = append(tx.Message.Instructions, solana.CompiledInstruction{
+			ProgramIDIndex: programIndex,
+			Accounts:       accounts,
+			Data:           outer.data,
+		})
+
+		var innerSet rpc.InnerInstruction
+		innerSet.Index = uint16(i)
+		for _, inner := range b.inner[i] {
+			programIndex, accounts := b.compile(inner)
+			height := inner.height
+			if b.noStackHeights {
+				height = 0
+			}
+			innerSet.Instructions = append(innerSet.Instructions, rpc.CompiledInstruction{
+				ProgramIDIndex: programIndex,
+				Accounts:       accounts,
+				Data:           inner.data,
+				StackHeight:    height,
+			})
+		}
+		if len(innerSet.Instructions) > 0 {
+			meta.InnerInstructions = append(meta.InnerInstructions, innerSet)
+		}
+
+		logs = append(logs, frameLogs(append([]*testInstruction{outer}, b.inner[i]...))...)
+	}
+	meta.LogMessages = logs
+
+	tx.Message.AccountKeys = b.keys
+	meta.PreBalances = make([]uint64, len(b.keys))
+	meta.PostBalances = make([]uint64, len(b.keys))
+	for account, balances := range b.lamports {
+		index := b.index(account)
+		meta.PreBalances[index], meta.PostBalances[index] = balances[0], balances[1]
+	}
+	return tx, &meta
+}
+
+// frameLogs writes the invoke, data and success lines of an outer instruction and its inner instructions, a frame's
+// data logs are written when it returns
+func frameLogs(instructions []*testInstruction) []string {
+	var logs []string
+	var stack []*testInstruction
+	closeFrame := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, data := range top.logs {
+			logs = append(logs, "Program data: "+base64.StdEncoding.EncodeToString(data))
+		}
+		logs = append(logs, fmt.Sprintf("Program %s success", top.program))
+	}
+	for _, instr := range instructions {
+		for len(stack) > 0 && stack[len(stack)-1].height >= instr.height {
+			closeFrame()
+		}
+		logs = append(logs, fmt.Sprintf("Program %s invoke [%d]", instr.program, instr.height))
+		stack = append(stack, instr)
+	}
+	for len(stack) > 0 {
+		closeFrame()
+	}
+	return logs
+}
+
+// parser returns a parser for the transaction with its logging silenced
+func (b *testTx) parser(t *testing.T) *Parser {
+	t.Helper()
+	tx, meta := b.build()
+	parser, err := NewTransactionParserFromTransaction(tx, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser.Log.SetOutput(io.Discard)
+	return parser
+}
+
+// parse runs ParseTransaction and ProcessSwapData over the transaction
+func (b *testTx) parse(t *testing.T) (*Parser, []SwapData, *SwapInfo) {
+	t.Helper()
+	parser := b.parser(t)
+	swapDatas, err := parser.ParseTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapInfo, err := parser.ProcessSwapData(swapDatas)
+	if err != nil {
+		t.Fatalf("ProcessSwapData: %s", err)
+	}
+	return parser, swapDatas, swapInfo
+}
+
+func tokenTransferData(amount uint64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{3}, amount)
+}
+
+func tokenTransferCheckedData(amount uint64, decimals uint8) []byte {
+	return append(binary.LittleEndian.AppendUint64([]byte{12}, amount), decimals)
+}
+
+// borsh serializes values one after the other
+func borsh(t *testing.T, values ...interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	encoder := ag_binary.NewBorshEncoder(&buf)
+	for _, value := range values {
+		if err := encoder.Encode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// anchorEventData returns the data of an anchor self-CPI event instruction
+func anchorEventData(t *testing.T, discriminator [16]byte, event interface{}) []byte {
+	t.Helper()
+	return append(discriminator[:], borsh(t, event)...)
+}
+
+// anchorLogData returns the payload of an anchor "Program data" event log
+func anchorLogData(t *testing.T, discriminator [8]byte, event interface{}) []byte {
+	t.Helper()
+	return append(discriminator[:], borsh(t, event)...)
+}
+
+// singleSwap returns the only decoded swap data of the given type
+func singleSwap[T any](t *testing.T, swapDatas []SwapData) T {
+	t.Helper()
+	var found []T
+	for _, swapData := range swapDatas {
+		if data, ok := swapData.Data.(T); ok {
+			found = append(found, data)
+		}
+	}
+	if len(found) != 1 {
+		var zero T
+		t.Fatalf("got %d %T in %d swap data, want 1", len(found), zero, len(swapDatas))
+	}
+	return found[0]
+}
+
+func assertSwap(t *testing.T, swapInfo *SwapInfo, inMint solana.PublicKey, inAmount uint64, outMint solana.PublicKey, outAmount uint64) {
+	t.Helper()
+	if !swapInfo.TokenInMint.Equals(inMint) || swapInfo.TokenInAmount != inAmount {
+		t.Errorf("token in %d %s, want %d %s", swapInfo.TokenInAmount, swapInfo.TokenInMint, inAmount, inMint)
+	}
+	if !swapInfo.TokenOutMint.Equals(outMint) || swapInfo.TokenOutAmount != outAmount {
+		t.Errorf("token out %d %s, want %d %s", swapInfo.TokenOutAmount, swapInfo.TokenOutMint, outAmount, outMint)
+	}
+}
+
+// anchorSwapData is the data of an anchor "swap" instruction, the decoders take the trade from events and transfers
+// so the arguments are left out
+var anchorSwapData = []byte{248, 198, 158, 145, 225, 117, 135, 200}
+
+// testPool is a pool's vaults and the trader's token accounts of its two mints
+type testPool struct {
+	address        solana.PublicKey
+	mintA, mintB   solana.PublicKey
+	vaultA, vaultB solana.PublicKey
+	userA, userB   solana.PublicKey
+}
+
+// newTestPool records the token accounts of a pool trading mintA against mintB
+func newTestPool(b *testTx, name string, trader, mintA solana.PublicKey, decimalsA uint8, mintB solana.PublicKey, decimalsB uint8) testPool {
+	pool := testPool{
+		address: testKey(name),
+		mintA:   mintA,
+		mintB:   mintB,
+		vaultA:  testKey(name + "/vault-a"),
+		vaultB:  testKey(name + "/vault-b"),
+		userA:   testKey(trader.String() + "/" + mintA.String()),
+		userB:   testKey(trader.String() + "/" + mintB.String()),
+	}
+	b.tokenAccount(pool.vaultA, pool.address, mintA, decimalsA, 0, 0)
+	b.tokenAccount(pool.vaultB, pool.address, mintB, decimalsB, 0, 0)
+	b.tokenAccount(pool.userA, trader, mintA, decimalsA, 0, 0)
+	b.tokenAccount(pool.userB, trader, mintB, decimalsB, 0, 0)
+	return pool
+}
+
+var (
+	testTrader = testKey("trader")
+	testMint   = testKey("mint")
+	testUSDC   = testKey("usdc")
+	testUSDT   = testKey("usdt")
+)
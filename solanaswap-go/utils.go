@@ -1,6 +1,10 @@
 package solanaswapgo
 
 import (
+	"encoding/base64"
+	"math/bits"
+	"strings"
+
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 )
@@ -12,3 +16,152 @@ func (p *Parser) convertRPCToSolanaInstruction(rpcInst rpc.CompiledInstruction)
 		Data:           rpcInst.Data,
 	}
 }
+
+// getAllInstructions returns the outer instructions of the transaction followed by every inner instruction
+func (p *Parser) getAllInstructions() []solana.CompiledInstruction {
+	instructions := append([]solana.CompiledInstruction{}, p.txInfo.Message.Instructions...)
+	if p.txMeta != nil {
+		for _, innerSet := range p.txMeta.InnerInstructions {
+			for _, inner := range innerSet.Instructions {
+				instructions = append(instructions, p.convertRPCToSolanaInstruction(inner))
+			}
+		}
+	}
+	return instructions
+}
+
+// getProgramDataLogs returns the decoded "Program data:" entries logged by programID itself (anchor emit!)
+// while the given outer instruction was executing. While an invocation is in scope only the entries of the
+// programID frames inside its subtree are returned.
+func (p *Parser) getProgramDataLogs(instructionIndex int, programID solana.PublicKey) [][]byte {
+	if p.txMeta == nil {
+		return nil
+	}
+
+	// frames of programID are matched to call tree nodes by their order of invocation
+	var scopedFrames map[int]bool
+	if p.scope != nil && p.scope.OuterIndex == instructionIndex {
+		scopedFrames = p.scopedInvocationOrdinals(programID)
+	}
+
+	type frame struct {
+		program string
+		ordinal int
+	}
+	var result [][]byte
+	var stack []frame
+	outerIndex := -1
+	invocations := 0
+	program := programID.String()
+
+	for _, line := range p.txMeta.LogMessages {
+		switch {
+		case strings.HasPrefix(line, "Program data: "):
+			if outerIndex != instructionIndex || len(stack) == 0 || stack[len(stack)-1].program != program {
+				continue
+			}
+			if scopedFrames != nil && !scopedFrames[stack[len(stack)-1].ordinal] {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(line, "Program data: "))
+			if len(fields) == 0 {
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(fields[0])
+			if err != nil {
+				continue
+			}
+			result = append(result, decoded)
+
+		case strings.HasPrefix(line, "Program ") && strings.Contains(line, " invoke ["):
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[2] != "invoke" || !isProgramLogKey(fields[1]) {
+				continue
+			}
+			if fields[3] == "[1]" {
+				outerIndex++
+				stack = stack[:0]
+				invocations = 0
+			}
+			current := frame{program: fields[1], ordinal: -1}
+			if current.program == program {
+				current.ordinal = invocations
+				invocations++
+			}
+			stack = append(stack, current)
+
+		case strings.HasPrefix(line, "Program "):
+			// only the runtime's "Program <id> success" and "Program <id> failed: <error>" lines end a frame,
+			// a program logging "success" or "failed" itself does not
+			fields := strings.Fields(line)
+			if len(fields) < 3 || len(stack) == 0 || fields[1] != stack[len(stack)-1].program {
+				continue
+			}
+			if (len(fields) == 3 && fields[2] == "success") || fields[2] == "failed:" {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return result
+}
+
+// isProgramLogKey reports whether a log line field is a program ID, rather than a word of a "Program log:" line
+func isProgramLogKey(field string) bool {
+	_, err := solana.PublicKeyFromBase58(field)
+	return err == nil
+}
+
+// getInvocationLogs returns the "Program data" logs emitted by one invocation of a program, the logs of its other
+// invocations in the same outer instruction are left out
+func (p *Parser) getInvocationLogs(node *InstructionNode) [][]byte {
+	scope := p.scope
+	p.scope = node
+	defer func() { p.scope = scope }()
+	return p.getProgramDataLogs(node.OuterIndex, node.ProgramID)
+}
+
+// scopedInvocationOrdinals returns the positions, among the invocations of programID within the scoped outer
+// instruction, of those lying inside the scoped invocation's subtree
+func (p *Parser) scopedInvocationOrdinals(programID solana.PublicKey) map[int]bool {
+	tree := p.GetCallTree()
+	ordinals := make(map[int]bool)
+	if p.scope.OuterIndex >= len(tree) {
+		return ordinals
+	}
+
+	inScope := map[*InstructionNode]bool{p.scope: true}
+	for _, node := range p.scope.descendants() {
+		inScope[node] = true
+	}
+
+	root := tree[p.scope.OuterIndex]
+	ordinal := 0
+	for _, node := range append([]*InstructionNode{root}, root.descendants()...) {
+		if !node.ProgramID.Equals(programID) {
+			continue
+		}
+		if inScope[node] {
+			ordinals[ordinal] = true
+		}
+		ordinal++
+	}
+	return ordinals
+}
+
+// mulDivFloor returns a*b/denominator rounded down, b must not exceed denominator
+func mulDivFloor(a, b, denominator uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	quotient, _ := bits.Div64(hi, lo, denominator)
+	return quotient
+}
+
+// mulDivCeil returns a*b/denominator rounded up, b must not exceed denominator
+func mulDivCeil(a, b, denominator uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	quotient, remainder := bits.Div64(hi, lo, denominator)
+	if remainder > 0 {
+		quotient++
+	}
+	return quotient
+}
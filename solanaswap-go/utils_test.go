@@ -0,0 +1,80 @@
+package solanaswapgo
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		a, b, denominator uint64
+		floor, ceil       uint64
+	}{
+		{0, 2500, 1_000_000, 0, 0},
+		{1_000_000_000, 2500, 1_000_000, 2_500_000, 2_500_000},
+		{1_000_000_001, 2500, 1_000_000, 2_500_000, 2_500_001},
+		{7, 1, 3, 2, 3},
+		// a*b overflows 64 bits
+		{math.MaxUint64, 999_999, 1_000_000, 18446725626965477905, 18446725626965477906},
+		{math.MaxUint64, 1_000_000, 1_000_000, math.MaxUint64, math.MaxUint64},
+	}
+	for _, tt := range tests {
+		if got := mulDivFloor(tt.a, tt.b, tt.denominator); got != tt.floor {
+			t.Errorf("mulDivFloor(%d, %d, %d) = %d, want %d", tt.a, tt.b, tt.denominator, got, tt.floor)
+		}
+		if got := mulDivCeil(tt.a, tt.b, tt.denominator); got != tt.ceil {
+			t.Errorf("mulDivCeil(%d, %d, %d) = %d, want %d", tt.a, tt.b, tt.denominator, got, tt.ceil)
+		}
+	}
+}
+
+// a program logging "success" or "failed" does not end its frame, only the runtime's result lines do
+func TestGetProgramDataLogsFrames(t *testing.T) {
+	outer, inner := testKey("outer-program").String(), testKey("inner-program").String()
+	data := func(value string) string {
+		return "Program data: " + base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	parser := &Parser{txMeta: &rpc.TransactionMeta{LogMessages: []string{
+		"Program " + outer + " invoke [1]",
+		"Program " + inner + " invoke [2]",
+		"Program log: quote failed, retrying",
+		"Program log: Instruction: success",
+		data("inner"),
+		"Program " + inner + " consumed 100 of 200 compute units",
+		"Program " + inner + " success",
+		data("outer"),
+		"Program " + inner + " invoke [2]",
+		"Program " + inner + " failed: custom program error: 0x1",
+		data("outer after failure"),
+		"Program " + outer + " success",
+	}}}
+
+	tests := []struct {
+		program string
+		want    []string
+	}{
+		{inner, []string{"inner"}},
+		{outer, []string{"outer", "outer after failure"}},
+	}
+	for _, tt := range tests {
+		logs := parser.getProgramDataLogs(0, solana.MustPublicKeyFromBase58(tt.program))
+		var got []string
+		for _, log := range logs {
+			got = append(got, string(log))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("program %s logs %q, want %q", tt.program, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("program %s logs %q, want %q", tt.program, got, tt.want)
+				break
+			}
+		}
+	}
+}